@@ -0,0 +1,61 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package api
+
+import (
+	"gopkg.in/yaml.v3"
+
+	"github.com/gdt-dev/core/parse"
+)
+
+// CleanupPolicy describes when a test runner executes the cleanup functions
+// registered in the results of a scenario's test specs.
+type CleanupPolicy string
+
+const (
+	// CleanupAlways executes cleanup functions regardless of whether the
+	// scenario succeeded or failed. This is the default.
+	CleanupAlways CleanupPolicy = "always"
+	// CleanupOnSuccess executes cleanup functions only if the scenario
+	// succeeded. This is useful for leaving resources in place to inspect
+	// after a failure.
+	CleanupOnSuccess CleanupPolicy = "on-success"
+	// CleanupOnFailure executes cleanup functions only if the scenario
+	// failed.
+	CleanupOnFailure CleanupPolicy = "on-failure"
+)
+
+// UnmarshalYAML validates that the YAML node contains a known CleanupPolicy.
+func (c *CleanupPolicy) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return parse.ExpectedScalarAt(node)
+	}
+	switch CleanupPolicy(node.Value) {
+	case CleanupAlways, CleanupOnSuccess, CleanupOnFailure:
+		*c = CleanupPolicy(node.Value)
+		return nil
+	default:
+		return parse.ExpectedOneOfAt(
+			node,
+			string(CleanupAlways),
+			string(CleanupOnSuccess),
+			string(CleanupOnFailure),
+		)
+	}
+}
+
+// Applies returns true if cleanup functions should be executed for a scenario
+// that failed (or not) according to the policy. An empty policy is treated as
+// `CleanupAlways`.
+func (c CleanupPolicy) Applies(failed bool) bool {
+	switch c {
+	case CleanupOnSuccess:
+		return !failed
+	case CleanupOnFailure:
+		return failed
+	default:
+		return true
+	}
+}
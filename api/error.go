@@ -35,6 +35,18 @@ var (
 	// ErrUnexpectedError is an ErrFailure when an unexpected error has
 	// occurred.
 	ErrUnexpectedError = fmt.Errorf("%w: unexpected error", ErrFailure)
+	// ErrNotMatch is an ErrFailure when an observed thing doesn't match an
+	// expected pattern.
+	ErrNotMatch = fmt.Errorf("%w: not match", ErrFailure)
+	// ErrOutOfRange is an ErrFailure when an observed quantity falls outside
+	// of an expected range.
+	ErrOutOfRange = fmt.Errorf("%w: out of range", ErrFailure)
+	// ErrNotPrefix is an ErrFailure when an observed thing doesn't start with
+	// an expected prefix.
+	ErrNotPrefix = fmt.Errorf("%w: not prefix", ErrFailure)
+	// ErrNotSuffix is an ErrFailure when an observed thing doesn't end with
+	// an expected suffix.
+	ErrNotSuffix = fmt.Errorf("%w: not suffix", ErrFailure)
 )
 
 // TimeoutExceeded returns an ErrTimeoutExceeded when a test's execution
@@ -91,6 +103,54 @@ func NoneIn(elements, container interface{}) error {
 	)
 }
 
+// NotMatch returns an ErrNotMatch when an observed thing doesn't match an
+// expected pattern.
+func NotMatch(pattern, container interface{}) error {
+	return fmt.Errorf(
+		"%w: expected %v to match %v",
+		ErrNotMatch, container, pattern,
+	)
+}
+
+// OutOfRange returns an ErrOutOfRange when an observed quantity of a named
+// thing falls outside of the expected minimum and maximum. A negative min or
+// max indicates no lower or upper bound.
+func OutOfRange(what string, min, max, got int) error {
+	var bounds string
+	switch {
+	case min == max:
+		bounds = fmt.Sprintf("exactly %d", min)
+	case min < 0:
+		bounds = fmt.Sprintf("at most %d", max)
+	case max < 0:
+		bounds = fmt.Sprintf("at least %d", min)
+	default:
+		bounds = fmt.Sprintf("between %d and %d", min, max)
+	}
+	return fmt.Errorf(
+		"%w: expected %s %s but got %d",
+		ErrOutOfRange, bounds, what, got,
+	)
+}
+
+// NotPrefix returns an ErrNotPrefix when an observed thing doesn't start with
+// an expected prefix.
+func NotPrefix(prefix, container interface{}) error {
+	return fmt.Errorf(
+		"%w: expected %v to start with %v",
+		ErrNotPrefix, container, prefix,
+	)
+}
+
+// NotSuffix returns an ErrNotSuffix when an observed thing doesn't end with
+// an expected suffix.
+func NotSuffix(suffix, container interface{}) error {
+	return fmt.Errorf(
+		"%w: expected %v to end with %v",
+		ErrNotSuffix, container, suffix,
+	)
+}
+
 // UnexpectedError returns an ErrUnexpectedError when a supplied error is not
 // expected.
 func UnexpectedError(err error) error {
@@ -109,6 +169,18 @@ func UnknownSourceType(source interface{}) error {
 	return fmt.Errorf("%w: %T", ErrUnknownSourceType, source)
 }
 
+var (
+	// ErrNoTests indicates that a YAML file found when discovering the test
+	// scenarios in a directory did not contain any tests, e.g. because it
+	// isn't a gdt test scenario.
+	ErrNoTests = errors.New("no tests found in file")
+)
+
+// NoTests returns an ErrNoTests error describing the supplied file path.
+func NoTests(path string) error {
+	return fmt.Errorf("%w: %s", ErrNoTests, path)
+}
+
 var (
 	// RuntimeError is the base error class for all errors occurring during
 	// runtime (and not during the parsing of a scenario or spec)
@@ -120,12 +192,35 @@ var (
 		"%w: required fixture missing",
 		RuntimeError,
 	)
+	// ErrFixtureStart is returned when a required fixture fails to start.
+	ErrFixtureStart = fmt.Errorf(
+		"%w: fixture failed to start",
+		RuntimeError,
+	)
+	// ErrCleanup is returned when a cleanup function registered in a test
+	// spec's result returns an error or panics.
+	ErrCleanup = fmt.Errorf(
+		"%w: cleanup failed",
+		RuntimeError,
+	)
 	// ErrTimeoutConflict is returned when the Go test tool's timeout conflicts
 	// with either a total wait time or a timeout in a scenario or test spec
 	ErrTimeoutConflict = fmt.Errorf(
 		"%w: timeout conflict",
 		RuntimeError,
 	)
+	// ErrVarResolve is returned when the value of a variable declared in a
+	// test scenario's or test suite's `vars` field cannot be determined.
+	ErrVarResolve = fmt.Errorf(
+		"%w: failed to resolve variable",
+		RuntimeError,
+	)
+	// ErrVarExpand is returned when a variable reference in a test spec's
+	// contents cannot be expanded.
+	ErrVarExpand = fmt.Errorf(
+		"%w: failed to expand variable reference",
+		RuntimeError,
+	)
 )
 
 // RequiredFixtureMissing returns an ErrRequiredFixture with the supplied
@@ -134,6 +229,36 @@ func RequiredFixtureMissing(name string) error {
 	return fmt.Errorf("%w: %s", ErrRequiredFixture, name)
 }
 
+// FixtureStartFailed returns an ErrFixtureStart with the supplied fixture name
+// that wraps the supplied error returned from the fixture's Start method.
+func FixtureStartFailed(name string, err error) error {
+	return fmt.Errorf("%w: %s: %w", ErrFixtureStart, name, err)
+}
+
+// CleanupFailed returns an ErrCleanup that wraps the supplied error returned
+// from a cleanup function.
+func CleanupFailed(err error) error {
+	return fmt.Errorf("%w: %w", ErrCleanup, err)
+}
+
+// CleanupPanicked returns an ErrCleanup describing the supplied value
+// recovered from a panicking cleanup function.
+func CleanupPanicked(recovered any) error {
+	return fmt.Errorf("%w: panic: %v", ErrCleanup, recovered)
+}
+
+// VarResolveFailed returns an ErrVarResolve for the named variable that wraps
+// the supplied error describing why its value could not be determined.
+func VarResolveFailed(name string, err error) error {
+	return fmt.Errorf("%w: %s: %w", ErrVarResolve, name, err)
+}
+
+// VarExpandFailed returns an ErrVarExpand for the supplied subject that wraps
+// the supplied error describing why a reference in it could not be expanded.
+func VarExpandFailed(subject string, err error) error {
+	return fmt.Errorf("%w: %q: %w", ErrVarExpand, subject, err)
+}
+
 // TimeoutConflict returns an ErrTimeoutConflict describing how the Go test
 // tool's timeout conflicts with either a total wait time or a timeout value
 // from a scenario or spec.
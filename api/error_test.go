@@ -21,3 +21,21 @@ func TestUnknownSourceType(t *testing.T) {
 	err = api.UnknownSourceType(source)
 	assert.ErrorContains(err, "[]string")
 }
+
+func TestOutOfRange(t *testing.T) {
+	assert := assert.New(t)
+
+	err := api.OutOfRange("lines", 2, 2, 1)
+	assert.ErrorIs(err, api.ErrOutOfRange)
+	assert.ErrorIs(err, api.ErrFailure)
+	assert.ErrorContains(err, "expected exactly 2 lines but got 1")
+
+	err = api.OutOfRange("lines", 2, -1, 1)
+	assert.ErrorContains(err, "expected at least 2 lines but got 1")
+
+	err = api.OutOfRange("lines", -1, 2, 3)
+	assert.ErrorContains(err, "expected at most 2 lines but got 3")
+
+	err = api.OutOfRange("lines", 1, 2, 3)
+	assert.ErrorContains(err, "expected between 1 and 2 lines but got 3")
+}
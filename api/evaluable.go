@@ -26,3 +26,16 @@ type Evaluable interface {
 	// Timeout returns the Evaluable's Timeout override, if any
 	Timeout() *Timeout
 }
+
+// PathResolver is an optional interface that an Evaluable may implement when
+// its test spec refers to files by relative path. The scenario calls
+// ResolvePaths once the test spec is parsed so that missing files are
+// reported as parse errors, with the location of the offending field, rather
+// than when the test spec is evaluated.
+type PathResolver interface {
+	// ResolvePaths resolves relative file paths in the test spec against the
+	// supplied base directory, the directory containing the test scenario,
+	// and returns an error if a referenced file does not exist. An empty
+	// base directory means the current working directory.
+	ResolvePaths(baseDir string) error
+}
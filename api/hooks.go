@@ -0,0 +1,70 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package api
+
+// HookEvent describes the outcome of a test spec that triggers a hook.
+type HookEvent string
+
+const (
+	// HookFail triggers a hook when the test spec fails.
+	HookFail HookEvent = "fail"
+	// HookSuccess triggers a hook when the test spec succeeds.
+	HookSuccess HookEvent = "success"
+	// HookAlways triggers a hook regardless of the test spec's outcome.
+	HookAlways HookEvent = "always"
+)
+
+// Hooks contains test specs, which may be handled by any plugin, that are
+// evaluated after a test spec depending on its outcome. Hooks are typically
+// used to collect debugging information, e.g. `kubectl describe`, when a test
+// spec fails.
+//
+//	tests:
+//	  - exec: nc -z $HOST $PORT
+//	    on:
+//	      fail:
+//	        exec: grep ERROR /var/log/myapp.log
+type Hooks struct {
+	// Fail contains test specs to evaluate if the test spec fails.
+	Fail []Evaluable
+	// Success contains test specs to evaluate if the test spec succeeds.
+	Success []Evaluable
+	// Always contains test specs to evaluate regardless of whether the test
+	// spec succeeds or fails. These are evaluated after any Fail or Success
+	// hooks.
+	Always []Evaluable
+}
+
+// For returns the test specs to evaluate for the supplied triggering event.
+func (h *Hooks) For(event HookEvent) []Evaluable {
+	if h == nil {
+		return nil
+	}
+	switch event {
+	case HookFail:
+		return h.Fail
+	case HookSuccess:
+		return h.Success
+	case HookAlways:
+		return h.Always
+	default:
+		return nil
+	}
+}
+
+// HookResult describes the outcome of evaluating a hook.
+type HookResult struct {
+	// Event is the event that triggered the hook.
+	Event HookEvent
+	// Name is the title of the hook's test spec.
+	Name string
+	// Output is any output from the hook's action, e.g. the stdout and
+	// stderr of an executed command.
+	Output string
+	// Failures is the collection of assertion failures from the hook.
+	Failures []error
+	// Err is a runtime error that occurred while evaluating the hook, if any.
+	Err error
+}
@@ -0,0 +1,41 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package api
+
+import (
+	"gopkg.in/yaml.v3"
+
+	"github.com/gdt-dev/core/parse"
+)
+
+// OnFailure describes whether a test runner continues executing the
+// remaining test specs in a scenario after a test spec fails.
+type OnFailure string
+
+const (
+	// OnFailureStop stops executing the scenario's remaining test specs after
+	// a test spec fails. This is the default.
+	OnFailureStop OnFailure = "stop"
+	// OnFailureContinue continues executing the scenario's remaining test
+	// specs after a test spec fails.
+	OnFailureContinue OnFailure = "continue"
+)
+
+// UnmarshalYAML validates that the YAML node contains a known OnFailure
+// policy.
+func (o *OnFailure) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return parse.ExpectedScalarAt(node)
+	}
+	switch OnFailure(node.Value) {
+	case OnFailureStop, OnFailureContinue:
+		*o = OnFailure(node.Value)
+		return nil
+	default:
+		return parse.ExpectedOneOfAt(
+			node, string(OnFailureStop), string(OnFailureContinue),
+		)
+	}
+}
@@ -20,13 +20,23 @@ type Result struct {
 	// that occurred during Eval(). These are *not* `gdterrors.RuntimeError`.
 	failures []error
 	// cleanups is the collection of cleanup functions that should be executed
-	// if the Result succeeded.
+	// after the scenario's test specs have run. Whether they are executed
+	// depends on the scenario's cleanup policy.
 	cleanups []func()
+	// errCleanups is the collection of cleanup functions that may return an
+	// error. They are executed alongside cleanups.
+	errCleanups []func() error
 	// data is a map, keyed by plugin name, of data about the spec run. Plugins
 	// can place anything they want in here and grab it from the context with
 	// the `gdtcontext.PriorRunData()` function. Plugins are responsible for
 	// clearing and setting any used prior run data.
 	data map[string]any
+	// output is any output from the spec's action, e.g. the stdout and stderr
+	// of an executed command, that is useful for debugging.
+	output string
+	// hooks is the collection of results of the hooks evaluated after the
+	// spec.
+	hooks []HookResult
 }
 
 // HasData returns true if any of the run data has been set, false otherwise.
@@ -58,16 +68,50 @@ func (r *Result) Cleanups() []func() {
 	return r.cleanups
 }
 
-// AddCleanup adds a cleanup function that will be executed if the Result has
-// successful.
+// CleanupsWithError returns the set of cleanup functions that may return an
+// error, added with AddCleanupWithError. Like Cleanups, the list returned is
+// in first-in, first-out order.
+func (r *Result) CleanupsWithError() []func() error {
+	return r.errCleanups
+}
+
+// AddCleanup adds a cleanup function that will be executed after the
+// scenario's test specs have run, according to the scenario's cleanup policy.
 func (r *Result) AddCleanup(fn func()) {
 	r.cleanups = append(r.cleanups, fn)
 }
 
+// AddCleanupWithError adds a cleanup function that may return an error. Any
+// returned error is reported by the test runner as an `ErrCleanup`.
+func (r *Result) AddCleanupWithError(fn func() error) {
+	r.errCleanups = append(r.errCleanups, fn)
+}
+
 // HasCleanups returns true if there are registered cleanup functions in the
 // Result.
 func (r *Result) HasCleanups() bool {
-	return len(r.cleanups) > 0
+	return len(r.cleanups) > 0 || len(r.errCleanups) > 0
+}
+
+// Output returns any output from the spec's action.
+func (r *Result) Output() string {
+	return r.output
+}
+
+// SetOutput sets the output from the spec's action.
+func (r *Result) SetOutput(output string) {
+	r.output = output
+}
+
+// Hooks returns the results of the hooks evaluated after the spec, in the
+// order they were evaluated.
+func (r *Result) Hooks() []HookResult {
+	return r.hooks
+}
+
+// AddHookResult adds the result of a hook evaluated after the spec.
+func (r *Result) AddHookResult(hr HookResult) {
+	r.hooks = append(r.hooks, hr)
 }
 
 // SetData sets a value in the result's run data cache.
@@ -95,6 +139,14 @@ func WithData(key string, val any) ResultModifier {
 	}
 }
 
+// WithOutput modifies the Result with the supplied output from the spec's
+// action
+func WithOutput(output string) ResultModifier {
+	return func(r *Result) {
+		r.SetOutput(output)
+	}
+}
+
 // WithFailures modifies the Result the supplied collection of assertion
 // failures
 func WithFailures(failures ...error) ResultModifier {
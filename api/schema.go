@@ -0,0 +1,112 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package api
+
+// Schema is a JSON Schema document, or a fragment of one, represented as a
+// map that can be encoded as JSON.
+type Schema map[string]any
+
+// SchemaProvider is an optional interface that a Plugin may implement to
+// describe the structure of its test specs and defaults. The schemas are
+// composed, along with the base test spec fields, into a JSON Schema
+// document for whole test scenario files that editors can use to help test
+// authors, and that test scenarios can optionally be validated against.
+//
+// Test specs handled by plugins that do not implement SchemaProvider are
+// accepted as any map.
+type SchemaProvider interface {
+	// SpecSchema returns the JSON Schema of the plugin's test specs. The
+	// schema should describe an object whose `properties` contain the
+	// plugin-specific fields of the test spec and whose `required` list
+	// contains the field(s) that identify the test spec as belonging to
+	// the plugin, e.g. `exec`. The base test spec fields, e.g. `name` and
+	// `timeout`, are added to the properties and need not be described.
+	SpecSchema() Schema
+	// DefaultsSchema returns the JSON Schema of the properties the plugin
+	// understands in a test scenario's `defaults` field, keyed by property
+	// name, e.g. `{"exec": {"type": "object", ...}}`. May return nil if the
+	// plugin has no defaults.
+	DefaultsSchema() Schema
+}
+
+// DurationSchema is the JSON Schema of a duration string, e.g. "1m30s".
+var DurationSchema = Schema{
+	"type":        "string",
+	"description": "a Go duration string, e.g. 30s or 1m30s",
+}
+
+// TimeoutSchema is the JSON Schema of a Timeout, which may be either a
+// duration string or an object with an `after` field. Like the Timeout,
+// Wait and Retry parsers, their schemas allow unknown fields, which are
+// ignored.
+var TimeoutSchema = Schema{
+	"oneOf": []any{
+		DurationSchema,
+		Schema{
+			"type": "object",
+			"properties": Schema{
+				"after": DurationSchema,
+			},
+		},
+	},
+}
+
+// WaitSchema is the JSON Schema of a Wait.
+var WaitSchema = Schema{
+	"type": "object",
+	"properties": Schema{
+		"before": DurationSchema,
+		"after":  DurationSchema,
+	},
+}
+
+// RetrySchema is the JSON Schema of a Retry.
+var RetrySchema = Schema{
+	"type": "object",
+	"properties": Schema{
+		"attempts": Schema{
+			"type":    "integer",
+			"minimum": 1,
+		},
+		"interval":    DurationSchema,
+		"exponential": Schema{"type": "boolean"},
+	},
+}
+
+// OnFailureSchema is the JSON Schema of an OnFailure policy.
+var OnFailureSchema = Schema{
+	"type": "string",
+	"enum": []any{string(OnFailureStop), string(OnFailureContinue)},
+}
+
+// BaseSpecSchema returns the JSON Schema properties of the base test spec
+// fields shared by all plugins' test specs. The supplied hookSpec is the
+// schema of a test spec that may be used as a hook in the `on` field.
+func BaseSpecSchema(hookSpec Schema) Schema {
+	hooks := Schema{
+		"oneOf": []any{
+			hookSpec,
+			Schema{"type": "array", "items": hookSpec},
+		},
+	}
+	return Schema{
+		"name":        Schema{"type": "string"},
+		"description": Schema{"type": "string"},
+		"plugin":      Schema{"type": "string"},
+		"timeout":     TimeoutSchema,
+		"wait":        WaitSchema,
+		"retry":       RetrySchema,
+		"on-failure":  OnFailureSchema,
+		"on": Schema{
+			"type": "object",
+			"properties": Schema{
+				string(HookFail):    hooks,
+				string(HookSuccess): hooks,
+				string(HookAlways):  hooks,
+			},
+			"additionalProperties": false,
+		},
+	}
+}
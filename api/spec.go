@@ -23,6 +23,9 @@ var (
 		"timeout",
 		"wait",
 		"retry",
+		"on-failure",
+		"on",
+		"plugin",
 	}
 )
 
@@ -32,6 +35,10 @@ var (
 type Spec struct {
 	// Plugin is a pointer to the plugin that successfully parsed the test spec
 	Plugin Plugin `yaml:"-"`
+	// PluginName is the name of the plugin that should parse the test spec.
+	// When empty, the test spec is offered to all registered plugins and
+	// exactly one of them must be able to parse it.
+	PluginName string `yaml:"plugin,omitempty"`
 	// Defaults contains the parsed defaults for the Spec. These are injected
 	// by the scenario during parse.
 	Defaults *Defaults `yaml:"-"`
@@ -47,6 +54,13 @@ type Spec struct {
 	Wait *Wait `yaml:"wait,omitempty"`
 	// Retry contains the retry configuration for the Spec
 	Retry *Retry `yaml:"retry,omitempty"`
+	// OnFailure overrides the scenario's policy for whether the remaining test
+	// specs in the scenario are executed when this Spec fails.
+	OnFailure OnFailure `yaml:"on-failure,omitempty"`
+	// On contains hooks, test specs handled by any plugin, that are evaluated
+	// after this Spec depending on its outcome. These are parsed by the
+	// scenario since they require the registered plugins.
+	On *Hooks `yaml:"-"`
 }
 
 // Title returns the Name of the scenario or the Path's file/base name if there
@@ -107,6 +121,11 @@ func (s *Spec) UnmarshalYAML(node *yaml.Node) error {
 				return parse.ExpectedScalarAt(valNode)
 			}
 			s.Description = valNode.Value
+		case "plugin":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			s.PluginName = valNode.Value
 		case "timeout":
 			var to *Timeout
 			switch valNode.Kind {
@@ -170,6 +189,12 @@ func (s *Spec) UnmarshalYAML(node *yaml.Node) error {
 				}
 			}
 			s.Retry = r
+		case "on-failure":
+			var o OnFailure
+			if err := valNode.Decode(&o); err != nil {
+				return err
+			}
+			s.OnFailure = o
 		}
 	}
 	return nil
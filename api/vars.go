@@ -0,0 +1,222 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gdt-dev/core/parse"
+)
+
+const (
+	// VarFormatText reads a variable's file as a string. This is the default.
+	VarFormatText = "text"
+	// VarFormatJSON decodes a variable's file as JSON.
+	VarFormatJSON = "json"
+	// VarFormatYAML decodes a variable's file as YAML.
+	VarFormatYAML = "yaml"
+)
+
+// Var describes a variable declared in the `vars` field of a test scenario or
+// test suite. A variable's value is either a literal value, the value of an
+// environment variable or the contents of a file.
+//
+//	vars:
+//	  REPLICAS: 3
+//	  HOST:
+//	    env: TEST_HOST
+//	    default: localhost
+//	  TOKEN:
+//	    file: secrets/token.txt
+//	  CONFIG:
+//	    file: config.json
+//	    format: json
+//	  LABELS:
+//	    value:
+//	      app: nginx
+type Var struct {
+	// Name is the name of the variable.
+	Name string
+	// Value is the literal value of the variable.
+	Value any
+	// Env is the name of the environment variable to read the variable's
+	// value from.
+	Env string
+	// Default is the value of the variable when the environment variable in
+	// Env is not set.
+	Default any
+	// File is the path to a file to read the variable's value from. Relative
+	// paths are resolved against the test scenario's or test suite's
+	// directory.
+	File string
+	// Format describes how the contents of File are decoded: `text` (the
+	// default) for a string with any trailing newline removed, or `json` or
+	// `yaml` for a structured value whose fields may be referenced.
+	Format string
+
+	// hasDefault is true when a default value was supplied, which may be
+	// nil or empty.
+	hasDefault bool
+}
+
+// UnmarshalYAML is a custom unmarshaler that understands a literal scalar or
+// sequence value as well as a map describing the variable's source.
+func (v *Var) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return node.Decode(&v.Value)
+	}
+	hasValue := false
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		switch key {
+		case "value":
+			if err := valNode.Decode(&v.Value); err != nil {
+				return err
+			}
+			hasValue = true
+		case "env":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			v.Env = valNode.Value
+		case "default":
+			if err := valNode.Decode(&v.Default); err != nil {
+				return err
+			}
+			v.hasDefault = true
+		case "file":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			v.File = valNode.Value
+		case "format":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			switch valNode.Value {
+			case VarFormatText, VarFormatJSON, VarFormatYAML:
+				v.Format = valNode.Value
+			default:
+				return parse.ExpectedOneOfAt(
+					valNode, VarFormatText, VarFormatJSON, VarFormatYAML,
+				)
+			}
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
+		}
+	}
+	sources := 0
+	for _, set := range []bool{hasValue, v.Env != "", v.File != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return parse.ExpectedVarSourceAt(node)
+	}
+	if v.hasDefault && v.Env == "" {
+		return parse.VarFieldRequiresAt("default", "env", node)
+	}
+	if v.Format != "" && v.File == "" {
+		return parse.VarFieldRequiresAt("format", "file", node)
+	}
+	return nil
+}
+
+// Resolve returns the value of the variable. Relative file paths are resolved
+// against the supplied directory.
+func (v *Var) Resolve(dir string) (any, error) {
+	switch {
+	case v.Env != "":
+		if val, ok := os.LookupEnv(v.Env); ok {
+			return val, nil
+		}
+		if v.hasDefault {
+			return v.Default, nil
+		}
+		return nil, VarResolveFailed(
+			v.Name, fmt.Errorf("environment variable %s is not set", v.Env),
+		)
+	case v.File != "":
+		fp := v.File
+		if dir != "" && !filepath.IsAbs(fp) {
+			fp = filepath.Join(dir, fp)
+		}
+		contents, err := os.ReadFile(fp)
+		if err != nil {
+			return nil, VarResolveFailed(v.Name, err)
+		}
+		var val any
+		switch v.Format {
+		case VarFormatJSON:
+			err = json.Unmarshal(contents, &val)
+		case VarFormatYAML:
+			err = yaml.Unmarshal(contents, &val)
+		default:
+			val = strings.TrimRight(string(contents), "\r\n")
+		}
+		if err != nil {
+			return nil, VarResolveFailed(v.Name, err)
+		}
+		return val, nil
+	default:
+		return v.Value, nil
+	}
+}
+
+// Vars is an ordered collection of variables declared in the `vars` field of
+// a test scenario or test suite.
+type Vars []*Var
+
+// UnmarshalYAML is a custom unmarshaler that preserves the order in which the
+// variables are declared.
+func (v *Vars) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return parse.ExpectedMapAt(node)
+	}
+	vars := Vars{}
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		va := &Var{}
+		if err := node.Content[i+1].Decode(va); err != nil {
+			return err
+		}
+		va.Name = keyNode.Value
+		vars = append(vars, va)
+	}
+	*v = vars
+	return nil
+}
+
+// Resolve returns a map, keyed by variable name, of the values of the
+// variables. Relative file paths are resolved against the supplied directory.
+func (v Vars) Resolve(dir string) (map[string]any, error) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+	res := make(map[string]any, len(v))
+	for _, va := range v {
+		val, err := va.Resolve(dir)
+		if err != nil {
+			return nil, err
+		}
+		res[va.Name] = val
+	}
+	return res, nil
+}
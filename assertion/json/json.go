@@ -8,13 +8,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"strconv"
 	"strings"
 
 	"github.com/theory/jsonpath"
 	gjs "github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
 
 	"github.com/gdt-dev/core/api"
+	gdtcontext "github.com/gdt-dev/core/context"
 )
 
 // Expect represents one or more assertions about JSON data responses
@@ -28,8 +31,13 @@ type Expect struct {
 	// that values found at the expression should have.
 	PathFormats map[string]string `yaml:"path-formats,omitempty"`
 	// Schema is a file path to the JSONSchema that the JSON should validate
-	// against.
+	// against. Relative file paths are resolved against the scenario's base
+	// directory by ResolvePaths or, failing that, against the context's base
+	// directory when the assertions are evaluated.
 	Schema string `yaml:"schema,omitempty"`
+	// schemaNode is the YAML node containing a relative Schema file path,
+	// used in reporting a missing file.
+	schemaNode *yaml.Node
 }
 
 // New returns a `api.Assertions` that asserts various conditions about
@@ -81,7 +89,7 @@ func (a *assertions) OK(ctx context.Context) bool {
 	if !a.pathFormatsOK() {
 		return false
 	}
-	if !a.schemaOK() {
+	if !a.schemaOK(ctx) {
 		return false
 	}
 	return true
@@ -220,7 +228,7 @@ func (a *assertions) pathFormatsOK() bool {
 
 // schemaOK returns true if the content matches the Schema condition, false
 // otherwise
-func (a *assertions) schemaOK() bool {
+func (a *assertions) schemaOK(ctx context.Context) bool {
 	if a == nil || a.exp == nil {
 		return true
 	}
@@ -229,6 +237,13 @@ func (a *assertions) schemaOK() bool {
 	}
 
 	schemaPath := a.exp.Schema
+	if !strings.HasPrefix(schemaPath, "file://") {
+		schemaPath = gdtcontext.ResolvePath(ctx, schemaPath)
+		if abs, err := filepath.Abs(schemaPath); err == nil {
+			schemaPath = abs
+		}
+		schemaPath = fileURL(schemaPath)
+	}
 	schemaLoader := gjs.NewReferenceLoader(schemaPath)
 	docLoader := gjs.NewStringLoader(string(a.content))
 
@@ -8,10 +8,12 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/gdt-dev/core/api"
 	gdtjson "github.com/gdt-dev/core/assertion/json"
+	gdtcontext "github.com/gdt-dev/core/context"
 	"github.com/gdt-dev/core/parse"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
@@ -282,3 +284,54 @@ func TestJSONPathKeyWithDot(t *testing.T) {
 	require.Len(failures, 1)
 	require.ErrorIs(failures[0], gdtjson.ErrJSONPathNotEqual)
 }
+
+func TestJSONSchemaRelativeToBaseDir(t *testing.T) {
+	require := require.New(t)
+
+	var exp gdtjson.Expect
+
+	// Relative schema paths are not resolved by the YAML decoder alone...
+	contents := []byte(`
+schema: books-schema.json
+`)
+	err := yaml.Unmarshal(contents, &exp)
+	require.Nil(err)
+	require.Equal("books-schema.json", exp.Schema)
+
+	// ... but against the context's base directory during evaluation.
+	ctx := gdtcontext.SetBaseDir(gdtcontext.New(), "testdata")
+	a := gdtjson.New(&exp, content())
+	require.True(a.OK(ctx))
+	require.Empty(a.Failures())
+
+	a = gdtjson.New(&exp, content())
+	require.False(a.OK(context.TODO()))
+	failures := a.Failures()
+	require.Len(failures, 1)
+	require.ErrorIs(failures[0], gdtjson.ErrJSONSchemaValidateError)
+}
+
+func TestJSONSchemaResolvePaths(t *testing.T) {
+	require := require.New(t)
+
+	contents := []byte(`
+schema: books-schema.json
+`)
+	var exp gdtjson.Expect
+	require.Nil(yaml.Unmarshal(contents, &exp))
+
+	err := exp.ResolvePaths("nonexistent")
+	require.NotNil(err)
+	var perr *parse.Error
+	require.ErrorAs(err, &perr)
+	require.Equal(2, perr.Line)
+	require.Equal(9, perr.Column)
+	require.Contains(perr.Message, "unable to find JSONSchema file")
+
+	require.Nil(exp.ResolvePaths("testdata"))
+	require.True(strings.HasPrefix(exp.Schema, "file://"))
+	require.True(strings.HasSuffix(exp.Schema, "books-schema.json"))
+
+	a := gdtjson.New(&exp, content())
+	require.True(a.OK(context.TODO()))
+}
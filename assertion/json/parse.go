@@ -72,6 +72,44 @@ func JSONPathInvalidNoRoot(path string, node *yaml.Node) error {
 	}
 }
 
+// fileURL returns a "file://" URL for the supplied absolute filepath that the
+// gojsonschema reference loader understands.
+func fileURL(path string) string {
+	if runtime.GOOS == "windows" {
+		// Need to do this because of an "optimization" done in the
+		// gojsonreference library:
+		// https://github.com/xeipuuv/gojsonreference/blob/bd5ef7bd5415a7ac448318e64f11a24cd21e594b/reference.go#L107-L114
+		return "file:///" + path
+	}
+	return "file://" + path
+}
+
+// ResolvePaths resolves a relative Schema file path against the supplied base
+// directory and returns a parse error, located at the `schema` field, if the
+// file does not exist. An empty base directory means the current working
+// directory.
+func (e *Expect) ResolvePaths(baseDir string) error {
+	if e == nil || e.Schema == "" || strings.HasPrefix(e.Schema, "file://") {
+		return nil
+	}
+	schemaPath := e.Schema
+	if baseDir != "" && !filepath.IsAbs(schemaPath) {
+		schemaPath = filepath.Join(baseDir, schemaPath)
+	}
+	if abs, err := filepath.Abs(schemaPath); err == nil {
+		schemaPath = abs
+	}
+	if _, err := os.Stat(schemaPath); err != nil {
+		node := e.schemaNode
+		if node == nil {
+			node = &yaml.Node{}
+		}
+		return JSONSchemaFileNotFound(schemaPath, node)
+	}
+	e.Schema = fileURL(schemaPath)
+	return nil
+}
+
 // UnmarshalYAML is a custom unmarshaler that ensures that JSONPath expressions
 // contained in the Expect are valid.
 func (e *Expect) UnmarshalYAML(node *yaml.Node) error {
@@ -107,24 +145,21 @@ func (e *Expect) UnmarshalYAML(node *yaml.Node) error {
 				// TODO(jaypipes): Support network lookups?
 				return UnsupportedJSONSchemaReference(schemaURL, valNode)
 			}
-			// Convert relative filepaths to absolute filepaths rooted in the context's
-			// testdir after stripping any "file://" scheme prefix
-			schemaURL = strings.TrimPrefix(schemaURL, "file://")
-			schemaURL, _ = filepath.Abs(schemaURL)
-
-			f, err := os.Open(schemaURL)
+			schemaPath := strings.TrimPrefix(schemaURL, "file://")
+			if !filepath.IsAbs(schemaPath) {
+				// Relative filepaths are resolved against the directory
+				// containing the test scenario, and checked, by ResolvePaths
+				// once the scenario knows the test spec.
+				e.Schema = schemaPath
+				e.schemaNode = valNode
+				continue
+			}
+			f, err := os.Open(schemaPath)
 			if err != nil {
-				return JSONSchemaFileNotFound(schemaURL, valNode)
+				return JSONSchemaFileNotFound(schemaPath, valNode)
 			}
 			defer f.Close()
-			if runtime.GOOS == "windows" {
-				// Need to do this because of an "optimization" done in the
-				// gojsonreference library:
-				// https://github.com/xeipuuv/gojsonreference/blob/bd5ef7bd5415a7ac448318e64f11a24cd21e594b/reference.go#L107-L114
-				e.Schema = "file:///" + schemaURL
-			} else {
-				e.Schema = "file://" + schemaURL
-			}
+			e.Schema = fileURL(schemaPath)
 		case "paths":
 			if valNode.Kind != yaml.MappingNode {
 				return parse.ExpectedMapAt(valNode)
@@ -8,6 +8,7 @@ import (
 	"context"
 	"io"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/samber/lo"
@@ -26,6 +27,9 @@ var (
 	fixturesKey    = ContextKey("gdt.fixtures")
 	runKey         = ContextKey("gdt.run")
 	unitKey        = ContextKey("gdt.unit")
+	baseDirKey     = ContextKey("gdt.basedir")
+	startedKey     = ContextKey("gdt.fixtures.started")
+	onFailureKey   = ContextKey("gdt.onfailure")
 )
 
 // ContextModifier sets some value on the context
@@ -147,6 +151,23 @@ func RegisterFixture(
 	return context.WithValue(ctx, fixturesKey, fixtures)
 }
 
+// SetFixturesStarted records in the context that the named fixtures have
+// already been started by an enclosing test suite. Scenarios that require one
+// of these fixtures neither start nor stop it themselves.
+func SetFixturesStarted(
+	ctx context.Context,
+	names ...string,
+) context.Context {
+	started := slices.Clone(StartedFixtures(ctx))
+	for _, name := range names {
+		lookup := strings.ToLower(name)
+		if !slices.Contains(started, lookup) {
+			started = append(started, lookup)
+		}
+	}
+	return context.WithValue(ctx, startedKey, started)
+}
+
 // RegisterPlugin registers a plugin with the context
 func RegisterPlugin(
 	ctx context.Context,
@@ -200,7 +221,11 @@ func PushTrace(
 	ctx context.Context,
 	name string,
 ) context.Context {
-	stack := TraceStack(ctx)
+	// NOTE: we copy the stack here because contexts derived from the same
+	// parent may be pushed onto from different goroutines (e.g. when a suite
+	// runs its scenarios in parallel) and appending to a shared backing array
+	// would clobber each other's trace names.
+	stack := slices.Clone(TraceStack(ctx))
 	stack = append(stack, name)
 	return context.WithValue(ctx, traceKey, stack)
 }
@@ -225,6 +250,27 @@ func SetTestUnit(
 	return context.WithValue(ctx, unitKey, tu)
 }
 
+// SetBaseDir sets the directory that relative file paths referenced by test
+// specs should be resolved against. Scenarios set this to the directory
+// containing the scenario file so that plugins never need to change the
+// process' working directory.
+func SetBaseDir(
+	ctx context.Context,
+	dir string,
+) context.Context {
+	return context.WithValue(ctx, baseDirKey, dir)
+}
+
+// SetOnFailure sets the default policy for whether a scenario's remaining test
+// specs are executed after a test spec fails. Test suites set this so that
+// their scenarios that do not declare their own policy use the suite's.
+func SetOnFailure(
+	ctx context.Context,
+	onFailure api.OnFailure,
+) context.Context {
+	return context.WithValue(ctx, onFailureKey, onFailure)
+}
+
 // New returns a new Context
 func New(mods ...ContextModifier) context.Context {
 	ctx := context.TODO()
@@ -6,11 +6,13 @@ package context_test
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 
 	"github.com/gdt-dev/core/api"
 	gdtcontext "github.com/gdt-dev/core/context"
 	"github.com/gdt-dev/core/fixture"
+	"github.com/gdt-dev/core/variable"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v3"
 )
@@ -88,3 +90,52 @@ func TestContext(t *testing.T) {
 	fixtures := gdtcontext.Fixtures(ctx)
 	assert.Len(fixtures, 1)
 }
+
+func TestResolvePath(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := gdtcontext.New()
+	assert.Equal("", gdtcontext.BaseDir(ctx))
+	assert.Equal("foo.json", gdtcontext.ResolvePath(ctx, "foo.json"))
+
+	ctx = gdtcontext.SetBaseDir(gdtcontext.New(), "/path/to/scenario")
+	assert.Equal("/path/to/scenario", gdtcontext.BaseDir(ctx))
+	assert.Equal(
+		filepath.Join("/path/to/scenario", "foo.json"),
+		gdtcontext.ResolvePath(ctx, "foo.json"),
+	)
+	assert.Equal(
+		filepath.Join("/path/to/scenario", "data", "foo.json"),
+		gdtcontext.ResolvePath(ctx, filepath.Join("data", "foo.json")),
+	)
+	abs := filepath.Join(string(filepath.Separator), "abs", "foo.json")
+	assert.Equal(abs, gdtcontext.ResolvePath(ctx, abs))
+}
+
+func TestExpand(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := gdtcontext.New()
+	ctx = gdtcontext.SetRun(ctx, map[string]any{
+		"ID":    "abc",
+		"ID2":   "def",
+		"count": int64(42),
+		"pod": map[string]any{
+			"name": "web-0",
+		},
+	})
+
+	assert.Equal("abc def", gdtcontext.ReplaceVariables(ctx, "$ID $ID2"))
+	assert.Equal("42", gdtcontext.ReplaceVariables(ctx, "${count}"))
+
+	got, err := gdtcontext.Expand(ctx, "pod ${pod.name} on ${node:-local}")
+	assert.Nil(err)
+	assert.Equal("pod web-0 on local", got)
+
+	_, err = gdtcontext.Expand(ctx, "${pod.ip}")
+	assert.ErrorIs(err, variable.ErrFieldNotFound)
+
+	val, err := gdtcontext.ExpandValue(ctx, "${count}")
+	assert.Nil(err)
+	assert.Equal(int64(42), val)
+}
@@ -6,13 +6,14 @@ package context
 
 import (
 	"context"
-	"fmt"
 	"io"
-	"strconv"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/gdt-dev/core/api"
 	"github.com/gdt-dev/core/testunit"
+	"github.com/gdt-dev/core/variable"
 )
 
 const (
@@ -87,6 +88,24 @@ func Fixtures(ctx context.Context) map[string]api.Fixture {
 	return map[string]api.Fixture{}
 }
 
+// StartedFixtures gets the lowercased names of the fixtures that have already
+// been started by an enclosing test suite
+func StartedFixtures(ctx context.Context) []string {
+	if ctx == nil {
+		return []string{}
+	}
+	if v := ctx.Value(startedKey); v != nil {
+		return v.([]string)
+	}
+	return []string{}
+}
+
+// FixtureStarted returns true if the named fixture has already been started by
+// an enclosing test suite
+func FixtureStarted(ctx context.Context, name string) bool {
+	return slices.Contains(StartedFixtures(ctx), strings.ToLower(name))
+}
+
 // Run gets a context's run data
 func Run(ctx context.Context) map[string]any {
 	if ctx == nil {
@@ -114,32 +133,86 @@ func TestUnit(ctx context.Context) *testunit.TestUnit {
 	return nil
 }
 
+// BaseDir gets a context's base directory, or the empty string if none is
+// set. An empty base directory means relative paths are resolved against the
+// process' current working directory.
+func BaseDir(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if v := ctx.Value(baseDirKey); v != nil {
+		return v.(string)
+	}
+	return ""
+}
+
+// OnFailure gets a context's default policy for whether a scenario's remaining
+// test specs are executed after a test spec fails, or the empty string if
+// none is set.
+func OnFailure(ctx context.Context) api.OnFailure {
+	if ctx == nil {
+		return ""
+	}
+	if v := ctx.Value(onFailureKey); v != nil {
+		return v.(api.OnFailure)
+	}
+	return ""
+}
+
+// ResolvePath returns the supplied file path resolved against the context's
+// base directory. Absolute paths, and any path when the context has no base
+// directory, are returned unchanged.
+//
+// Plugins should use this function to locate files referenced in test specs
+// instead of relying on the process' current working directory.
+func ResolvePath(ctx context.Context, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	dir := BaseDir(ctx)
+	if dir == "" {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// Expand returns the supplied subject with all references to variables in
+// the prior run data replaced with their stored values. See the variable
+// package for the supported reference syntax. References to undefined
+// variables are left untouched.
+//
+// An error is returned if a reference selects a field or element that does
+// not exist in a stored structured value.
+func Expand(
+	ctx context.Context,
+	subject string,
+) (string, error) {
+	return variable.Expand(subject, priorRunLookup(ctx))
+}
+
+// ExpandValue returns the stored value of the prior run variable referenced
+// by the supplied subject, preserving its type, if the subject consists of
+// exactly one variable reference, e.g. `${count}`. Otherwise, ExpandValue
+// returns the expanded subject string.
+func ExpandValue(
+	ctx context.Context,
+	subject string,
+) (any, error) {
+	return variable.Value(subject, priorRunLookup(ctx))
+}
+
 // ReplaceVariables replaces all occurrences of any of the variables in the
-// prior run data with their stored variable values
+// prior run data with their stored variable values. References that cannot be
+// resolved are left untouched. Use Expand to detect such references.
 func ReplaceVariables(
 	ctx context.Context,
 	subject string,
 ) string {
-	data := PriorRun(ctx)
-	for dataKey, dataVal := range data {
-		var dataValStr string
-		switch dataVal := dataVal.(type) {
-		case string:
-			dataValStr = dataVal
-		case []byte:
-			dataValStr = string(dataVal)
-		case int, uint, int8, int16, int32, int64:
-			dataValStr = strconv.Itoa(dataVal.(int))
-		case float32, float64:
-			dataValStr = strconv.FormatFloat(dataVal.(float64), 'f', -1, 64)
-		default:
-			continue
-		}
-		subject = strings.ReplaceAll(
-			subject,
-			fmt.Sprintf("$%s", dataKey),
-			dataValStr,
-		)
-	}
-	return subject
+	res, _ := Expand(ctx, subject)
+	return res
+}
+
+// priorRunLookup returns a variable.Lookup over the prior run data.
+func priorRunLookup(ctx context.Context) variable.Lookup {
+	return variable.MapLookup(PriorRun(ctx))
 }
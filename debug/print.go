@@ -8,10 +8,15 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 
 	gdtcontext "github.com/gdt-dev/core/context"
 )
 
+// writeLock serializes writes to the context's debug writers, which may be
+// shared between scenarios that are run in parallel.
+var writeLock sync.Mutex
+
 // Printf writes a message with optional message arguments to the context's
 // Debug output. The behaviour is analogous to `fmt.Printf`.
 func Printf(
@@ -34,10 +39,12 @@ func Printf(
 	}
 	msg += fmt.Sprintf(format, args...)
 	msg = strings.TrimSuffix(msg, "\n") + "\n"
+	writeLock.Lock()
 	for _, w := range writers {
 		//nolint:errcheck
 		w.Write([]byte(msg))
 	}
+	writeLock.Unlock()
 	if tu != nil {
 		tu.Log(strings.TrimSuffix(msg, "\n"))
 	}
@@ -64,10 +71,12 @@ func Println(
 		msg += " [" + trace + "] "
 	}
 	msg += fmt.Sprintln(args...)
+	writeLock.Lock()
 	for _, w := range writers {
 		//nolint:errcheck
 		w.Write([]byte(msg))
 	}
+	writeLock.Unlock()
 	if tu != nil {
 		tu.Log(strings.TrimSuffix(msg, "\n"))
 	}
@@ -0,0 +1,177 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package cleaner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+
+	"github.com/gdt-dev/core/api"
+	"github.com/gdt-dev/core/parse"
+	"github.com/gdt-dev/core/plugin"
+)
+
+func init() {
+	plugin.Register(&Plugin{})
+}
+
+const (
+	// CleanupOK registers a cleanup function that succeeds.
+	CleanupOK = "ok"
+	// CleanupError registers a cleanup function that returns an error.
+	CleanupError = "error"
+	// CleanupPanic registers a cleanup function that panics.
+	CleanupPanic = "panic"
+)
+
+var (
+	ranLock sync.Mutex
+	ran     []string
+)
+
+// Ran returns the labels of the specs whose cleanup functions have executed,
+// in order of execution.
+func Ran() []string {
+	ranLock.Lock()
+	defer ranLock.Unlock()
+	return slices.Clone(ran)
+}
+
+// Reset clears the record of executed cleanup functions.
+func Reset() {
+	ranLock.Lock()
+	defer ranLock.Unlock()
+	ran = nil
+}
+
+type Defaults struct{}
+
+func (d *Defaults) UnmarshalYAML(node *yaml.Node) error {
+	return nil
+}
+
+type Spec struct {
+	api.Spec
+	Clean   string `yaml:"clean"`
+	Failing bool   `yaml:"failing"`
+	Cleanup string `yaml:"cleanup"`
+}
+
+func (s *Spec) SetBase(b api.Spec) {
+	s.Spec = b
+}
+
+func (s *Spec) Base() *api.Spec {
+	return &s.Spec
+}
+
+func (s *Spec) Retry() *api.Retry {
+	return nil
+}
+
+func (s *Spec) Timeout() *api.Timeout {
+	return nil
+}
+
+func (s *Spec) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return parse.ExpectedMapAt(node)
+	}
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		switch key {
+		case "clean":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			s.Clean = valNode.Value
+		case "failing":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			failing, err := strconv.ParseBool(valNode.Value)
+			if err != nil {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			s.Failing = failing
+		case "cleanup":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			s.Cleanup = valNode.Value
+		default:
+			if lo.Contains(api.BaseSpecFields, key) {
+				continue
+			}
+			return parse.UnknownFieldAt(key, keyNode)
+		}
+	}
+	if s.Clean == "" {
+		return parse.UnknownFieldAt("clean", node)
+	}
+	return nil
+}
+
+func (s *Spec) Eval(ctx context.Context) (*api.Result, error) {
+	fails := []error{}
+	if s.Failing {
+		fails = append(fails, fmt.Errorf("%s failed", s.Clean))
+	}
+	res := api.NewResult(api.WithFailures(fails...))
+	label := s.Clean
+	switch s.Cleanup {
+	case CleanupError:
+		res.AddCleanupWithError(func() error {
+			record(label)
+			return errors.New(label + " cleanup error")
+		})
+	case CleanupPanic:
+		res.AddCleanup(func() {
+			record(label)
+			panic(label + " cleanup panic")
+		})
+	default:
+		res.AddCleanup(func() {
+			record(label)
+		})
+	}
+	return res, nil
+}
+
+func record(label string) {
+	ranLock.Lock()
+	defer ranLock.Unlock()
+	ran = append(ran, label)
+}
+
+type Plugin struct{}
+
+func (p *Plugin) Info() api.PluginInfo {
+	return api.PluginInfo{
+		Name: "cleaner",
+	}
+}
+
+func (p *Plugin) Defaults() yaml.Unmarshaler {
+	return &Defaults{}
+}
+
+func (p *Plugin) Specs() []api.Evaluable {
+	return []api.Evaluable{&Spec{}}
+}
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -87,24 +88,123 @@ func (e *Error) SetContents() {
 	}
 }
 
-// UnknownSpecAt returns an ErrUnknownSpec with the line/column of the supplied
-// YAML node.
-func UnknownSpecAt(path string, node *yaml.Node) error {
+// SpecRejection describes why a plugin could not parse a test spec
+// definition.
+type SpecRejection struct {
+	// Plugin is the name of the plugin.
+	Plugin string
+	// Err is the error returned by the plugin's test spec parser.
+	Err error
+	// Fields contains the names of the fields that the plugin's test specs
+	// accept, if known. When Err is an *UnknownFieldError, the closest
+	// matches to the unknown field are suggested from these names.
+	Fields []string
+}
+
+// String returns the plugin name and reason for the rejection along with any
+// suggested field names.
+func (r SpecRejection) String() string {
+	msg := fmt.Sprintf("%s: %s", r.Plugin, r.Err)
+	var ufe *UnknownFieldError
+	if errors.As(r.Err, &ufe) {
+		suggestions := ClosestMatches(ufe.Field, r.Fields)
+		if len(suggestions) > 0 {
+			quoted := make([]string, len(suggestions))
+			for x, s := range suggestions {
+				quoted[x] = strconv.Quote(s)
+			}
+			msg += fmt.Sprintf(
+				" (did you mean %s?)", strings.Join(quoted, " or "),
+			)
+		}
+	}
+	return msg
+}
+
+// UnknownSpecAt returns a parse error for when no plugin could parse the test
+// spec definition at the supplied YAML node. The message lists the supplied
+// reasons each plugin rejected the test spec definition.
+func UnknownSpecAt(
+	path string,
+	node *yaml.Node,
+	rejections ...SpecRejection,
+) error {
+	b := &strings.Builder{}
+	b.WriteString("no plugin could parse spec definition")
+	if len(rejections) > 0 {
+		b.WriteString(":")
+	}
+	for _, r := range rejections {
+		b.WriteString("\n  ")
+		b.WriteString(r.String())
+	}
+	return &Error{
+		Path:    path,
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: b.String(),
+	}
+}
+
+// AmbiguousSpecAt returns a parse error for when more than one plugin, named
+// in the supplied plugin names, could parse the test spec definition at the
+// supplied YAML node.
+func AmbiguousSpecAt(path string, node *yaml.Node, plugins ...string) error {
+	return &Error{
+		Path:   path,
+		Line:   node.Line,
+		Column: node.Column,
+		Message: fmt.Sprintf(
+			"ambiguous spec definition could be parsed by plugins %s; "+
+				"use the plugin field to choose one",
+			strings.Join(plugins, ", "),
+		),
+	}
+}
+
+// UnknownPluginAt returns a parse error for when the `plugin` field of a test
+// spec definition names a plugin that is not registered.
+func UnknownPluginAt(path string, name string, node *yaml.Node) error {
 	return &Error{
 		Path:    path,
 		Line:    node.Line,
 		Column:  node.Column,
-		Message: "no plugin could parse spec definition",
+		Message: fmt.Sprintf("unknown plugin %q", name),
 	}
 }
 
-// UnknownFieldAt returns an ErrUnknownField for a supplied field annotated
+// UnknownFieldError describes a field that a parser did not know. It wraps
+// ErrParseUnknownField.
+type UnknownFieldError struct {
+	// Field is the name of the unknown field.
+	Field string
+	// Line is the line number of the unknown field.
+	Line int
+	// Column is the column number of the unknown field.
+	Column int
+}
+
+// Error implements the error interface for UnknownFieldError.
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf(
+		"%s: %q at line %d, column %d",
+		ErrParseUnknownField, e.Field, e.Line, e.Column,
+	)
+}
+
+// Unwrap returns ErrParseUnknownField.
+func (e *UnknownFieldError) Unwrap() error {
+	return ErrParseUnknownField
+}
+
+// UnknownFieldAt returns an *UnknownFieldError for a supplied field annotated
 // with the line/column of the supplied YAML node.
 func UnknownFieldAt(field string, node *yaml.Node) error {
-	return fmt.Errorf(
-		"%w: %q at line %d, column %d",
-		ErrParseUnknownField, field, node.Line, node.Column,
-	)
+	return &UnknownFieldError{
+		Field:  field,
+		Line:   node.Line,
+		Column: node.Column,
+	}
 }
 
 // ExpectedMapAt returns a parse error for when a field that can contain a
@@ -219,6 +319,20 @@ func InvalidRetryAttemptsAt(node *yaml.Node, attempts int) error {
 	}
 }
 
+// ExpectedOneOfAt returns a parse error for when a scalar field did not contain
+// one of the supplied allowed values, annotated with the line/column of the
+// supplied YAML node.
+func ExpectedOneOfAt(node *yaml.Node, allowed ...string) error {
+	return &Error{
+		Line:   node.Line,
+		Column: node.Column,
+		Message: fmt.Sprintf(
+			"expected one of %s but got %q",
+			strings.Join(allowed, ", "), node.Value,
+		),
+	}
+}
+
 // FileNotFoundAt returns ErrFileNotFound for a given file path
 func FileNotFoundAt(path string, node *yaml.Node) error {
 	return &Error{
@@ -227,3 +341,36 @@ func FileNotFoundAt(path string, node *yaml.Node) error {
 		Message: fmt.Sprintf("file not found: %q", path),
 	}
 }
+
+// ExpectedVarSourceAt returns a parse error for when a variable declaration
+// does not contain exactly one of a literal value, an environment variable
+// name or a file path.
+func ExpectedVarSourceAt(node *yaml.Node) error {
+	return &Error{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: "expected exactly one of value, env or file in variable",
+	}
+}
+
+// VarFieldRequiresAt returns a parse error for when a field in a variable
+// declaration is only valid alongside another field that is missing.
+func VarFieldRequiresAt(field string, requires string, node *yaml.Node) error {
+	return &Error{
+		Line:   node.Line,
+		Column: node.Column,
+		Message: fmt.Sprintf(
+			"variable field %q is only valid with %q", field, requires,
+		),
+	}
+}
+
+// SchemaViolationAt returns a parse error for when a document does not
+// conform to its JSON Schema. The supplied message describes the violations.
+func SchemaViolationAt(node *yaml.Node, msg string) error {
+	return &Error{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: "schema validation failed:\n" + msg,
+	}
+}
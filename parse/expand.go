@@ -7,21 +7,304 @@ package parse
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strings"
-)
 
-const (
-	// hopefully nobody actually has an environment variable with this key!
-	dollarSignReplacementToken = "oiuqdfjhaso7t213041"
+	"gopkg.in/yaml.v3"
 )
 
-// ExpandWithFixedDoubleDollar expands the given string using os.ExpandEnv,
+// ExpandOption configures how environment variable references in test
+// scenario and test suite documents are expanded.
+type ExpandOption func(e *expander)
+
+// WithStrictEnv fails the expansion when a document refers to an environment
+// variable that is not set and has no default value. By default, such
+// references are replaced with the empty string.
+func WithStrictEnv(strict bool) ExpandOption {
+	return func(e *expander) {
+		e.strict = strict
+	}
+}
+
+// WithAllowedEnv restricts expansion to the environment variables with the
+// supplied names. References to any other variable are left untouched, which
+// allows them to be interpreted later, e.g. by a shell.
+func WithAllowedEnv(names ...string) ExpandOption {
+	return func(e *expander) {
+		e.allowed = append(e.allowed, names...)
+	}
+}
+
+// WithAllowedEnvPrefix restricts expansion to the environment variables whose
+// names start with one of the supplied prefixes. References to any other
+// variable are left untouched.
+func WithAllowedEnvPrefix(prefixes ...string) ExpandOption {
+	return func(e *expander) {
+		e.prefixes = append(e.prefixes, prefixes...)
+	}
+}
+
+// WithEnvLookup sets the function used to look up the values of environment
+// variables. Defaults to os.LookupEnv.
+func WithEnvLookup(lookup func(string) (string, bool)) ExpandOption {
+	return func(e *expander) {
+		e.lookup = lookup
+	}
+}
+
+// WithoutBlockScalars leaves the contents of literal (`|`) and folded (`>`)
+// block scalars, e.g. multi-line shell scripts, unexpanded.
+func WithoutBlockScalars() ExpandOption {
+	return func(e *expander) {
+		e.skipBlocks = true
+	}
+}
+
+// expander expands environment variable references.
+type expander struct {
+	strict     bool
+	allowed    []string
+	prefixes   []string
+	lookup     func(string) (string, bool)
+	skipBlocks bool
+}
+
+func newExpander(opts ...ExpandOption) *expander {
+	e := &expander{lookup: os.LookupEnv}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// isAllowed returns true if references to the named environment variable
+// should be expanded.
+func (e *expander) isAllowed(name string) bool {
+	if len(e.allowed) == 0 && len(e.prefixes) == 0 {
+		return true
+	}
+	if slices.Contains(e.allowed, name) {
+		return true
+	}
+	for _, p := range e.prefixes {
+		if strings.HasPrefix(name, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// expand returns the supplied subject with environment variable references
+// expanded. A "$$" is replaced with a single "$".
+func (e *expander) expand(subject string) (string, error) {
+	if !strings.Contains(subject, "$") {
+		return subject, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(subject); {
+		if subject[i] != '$' || i+1 == len(subject) {
+			b.WriteByte(subject[i])
+			i++
+			continue
+		}
+		next := subject[i+1]
+		switch {
+		case next == '$':
+			b.WriteByte('$')
+			i += 2
+		case next == '{':
+			end := strings.IndexByte(subject[i:], '}')
+			if end < 0 {
+				b.WriteString(subject[i:])
+				return b.String(), nil
+			}
+			raw := subject[i : i+end+1]
+			val, err := e.expandBraced(raw[2 : len(raw)-1])
+			if err != nil {
+				return "", err
+			}
+			if val == nil {
+				b.WriteString(raw)
+			} else {
+				b.WriteString(*val)
+			}
+			i += end + 1
+		case isEnvNameStart(next):
+			n := 1
+			for i+1+n < len(subject) && isEnvNameChar(subject[i+1+n]) {
+				n++
+			}
+			name := subject[i+1 : i+1+n]
+			raw := subject[i : i+1+n]
+			i += n + 1
+			if !e.isAllowed(name) {
+				b.WriteString(raw)
+				continue
+			}
+			val, ok := e.lookup(name)
+			if !ok && e.strict {
+				return "", fmt.Errorf("environment variable %q is not set", name)
+			}
+			b.WriteString(val)
+		default:
+			b.WriteByte('$')
+			i++
+		}
+	}
+	return b.String(), nil
+}
+
+// expandBraced returns the expansion of the contents of a `${...}` reference,
+// or nil if the reference should be left untouched.
+func (e *expander) expandBraced(ref string) (*string, error) {
+	name := ref
+	op := ""
+	arg := ""
+	if idx := strings.Index(ref, ":"); idx >= 0 && idx+1 < len(ref) {
+		switch ref[idx+1] {
+		case '-', '?':
+			name = ref[:idx]
+			op = ref[idx : idx+2]
+			arg = ref[idx+2:]
+		}
+	}
+	if !isEnvName(name) || !e.isAllowed(name) {
+		return nil, nil
+	}
+	val, ok := e.lookup(name)
+	if ok && val != "" {
+		return &val, nil
+	}
+	switch op {
+	case ":-":
+		return &arg, nil
+	case ":?":
+		if arg == "" {
+			arg = "is not set"
+		}
+		return nil, fmt.Errorf("environment variable %q %s", name, arg)
+	}
+	if !ok && e.strict {
+		return nil, fmt.Errorf("environment variable %q is not set", name)
+	}
+	return &val, nil
+}
+
+// expandNode expands environment variable references in all scalar nodes in
+// the supplied YAML node tree. Each node is only expanded once, even when it
+// is referred to by aliases.
+func (e *expander) expandNode(node *yaml.Node, seen map[*yaml.Node]bool) error {
+	if node == nil || seen[node] {
+		return nil
+	}
+	seen[node] = true
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if e.skipBlocks && node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+			return nil
+		}
+		val, err := e.expand(node.Value)
+		if err != nil {
+			return &Error{
+				Line:    node.Line,
+				Column:  node.Column,
+				Message: err.Error(),
+			}
+		}
+		if val != node.Value {
+			node.Value = val
+			// An untagged plain scalar's type is determined by its value,
+			// so we have it re-resolved, e.g. `replicas: $N` is an int.
+			if node.Style == 0 {
+				node.Tag = ""
+			}
+		}
+	case yaml.AliasNode:
+		// The anchored node is expanded where it is defined.
+	default:
+		for _, child := range node.Content {
+			if err := e.expandNode(child, seen); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Expand returns the supplied subject with references to environment
+// variables, e.g. `$VAR` or `${VAR}`, replaced with their values. A "$$" is
+// replaced with a single "$", which allows test authors to use the dollar
+// symbol in their test contents.
+//
+// In addition, `${VAR:-default}` expands to "default" if VAR is unset or
+// empty and `${VAR:?message}` fails with the supplied message if VAR is unset
+// or empty.
+//
+// Unlike os.ExpandEnv, Expand never modifies the process environment.
+func Expand(subject string, opts ...ExpandOption) (string, error) {
+	return newExpander(opts...).expand(subject)
+}
+
+// ExpandNode expands references to environment variables, as described for
+// Expand, in the values of all scalar nodes in the supplied YAML node tree.
+// Comments are never expanded. Any returned error is an *Error containing the
+// line and column of the offending scalar.
+func ExpandNode(node *yaml.Node, opts ...ExpandOption) error {
+	return newExpander(opts...).expandNode(node, map[*yaml.Node]bool{})
+}
+
+// UnmarshalExpanded parses the supplied YAML document, expands references to
+// environment variables in it using ExpandNode and decodes the result into
+// the supplied value.
+func UnmarshalExpanded(
+	contents []byte,
+	into any,
+	opts ...ExpandOption,
+) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(contents, &doc); err != nil {
+		return err
+	}
+	if doc.Kind == 0 {
+		// An empty document.
+		return nil
+	}
+	if err := ExpandNode(&doc, opts...); err != nil {
+		return err
+	}
+	return doc.Decode(into)
+}
+
+// ExpandWithFixedDoubleDollar expands the given string like os.ExpandEnv,
 // however unlike the default behaviour of replacing a string "$$VALUE" with
 // "VALUE", it replaces the "$$" witha single "$". This allows test authors to
 // use the dollar symbol in their test contents (they need to escape with
 // '$$').
+//
+// Deprecated: use Expand or ExpandNode, which support strict expansion.
 func ExpandWithFixedDoubleDollar(subject string) string {
-	os.Setenv(dollarSignReplacementToken, "$")
-	replaceStr := fmt.Sprintf("${%s}", dollarSignReplacementToken)
-	return os.ExpandEnv(strings.ReplaceAll(subject, "$$", replaceStr))
+	res, _ := Expand(subject)
+	return res
+}
+
+func isEnvNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isEnvNameChar(c byte) bool {
+	return isEnvNameStart(c) || (c >= '0' && c <= '9')
+}
+
+// isEnvName returns true if the supplied string is a valid environment
+// variable name.
+func isEnvName(s string) bool {
+	if s == "" || !isEnvNameStart(s[0]) {
+		return false
+	}
+	for x := 1; x < len(s); x++ {
+		if !isEnvNameChar(s[x]) {
+			return false
+		}
+	}
+	return true
 }
@@ -5,10 +5,12 @@
 package parse_test
 
 import (
+	"os"
 	"testing"
 
 	"github.com/gdt-dev/core/parse"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestExpandWithFixedDoubleDollar(t *testing.T) {
@@ -47,3 +49,174 @@ func TestExpandWithFixedDoubleDollar(t *testing.T) {
 		assert.Equal(c.exp, got)
 	}
 }
+
+func TestExpand(t *testing.T) {
+	t.Setenv("GDT_HOST", "example.com")
+	t.Setenv("GDT_EMPTY", "")
+	t.Setenv("OTHER", "other")
+
+	cases := []struct {
+		name    string
+		content string
+		opts    []parse.ExpandOption
+		exp     string
+		expErr  string
+	}{
+		{
+			name:    "plain",
+			content: "http://$GDT_HOST/",
+			exp:     "http://example.com/",
+		},
+		{
+			name:    "braces",
+			content: "${GDT_HOST}:8080",
+			exp:     "example.com:8080",
+		},
+		{
+			name:    "unset lenient",
+			content: "[$GDT_UNSET]",
+			exp:     "[]",
+		},
+		{
+			name:    "unset strict",
+			content: "[$GDT_UNSET]",
+			opts:    []parse.ExpandOption{parse.WithStrictEnv(true)},
+			expErr:  `environment variable "GDT_UNSET" is not set`,
+		},
+		{
+			name:    "empty strict",
+			content: "[${GDT_EMPTY}]",
+			opts:    []parse.ExpandOption{parse.WithStrictEnv(true)},
+			exp:     "[]",
+		},
+		{
+			name:    "default unset",
+			content: "${GDT_UNSET:-localhost}",
+			opts:    []parse.ExpandOption{parse.WithStrictEnv(true)},
+			exp:     "localhost",
+		},
+		{
+			name:    "default empty",
+			content: "${GDT_EMPTY:-localhost}",
+			exp:     "localhost",
+		},
+		{
+			name:    "default set",
+			content: "${GDT_HOST:-localhost}",
+			exp:     "example.com",
+		},
+		{
+			name:    "required set",
+			content: "${GDT_HOST:?must be set}",
+			exp:     "example.com",
+		},
+		{
+			name:    "required unset",
+			content: "${GDT_UNSET:?must be set to the service host}",
+			expErr:  `environment variable "GDT_UNSET" must be set to the service host`,
+		},
+		{
+			name:    "escaped",
+			content: "$$GDT_HOST $${GDT_HOST}",
+			opts:    []parse.ExpandOption{parse.WithStrictEnv(true)},
+			exp:     "$GDT_HOST ${GDT_HOST}",
+		},
+		{
+			name:    "not a reference",
+			content: "$.items[0] costs $5 ${not.a.var} $",
+			opts:    []parse.ExpandOption{parse.WithStrictEnv(true)},
+			exp:     "$.items[0] costs $5 ${not.a.var} $",
+		},
+		{
+			name:    "allowed",
+			content: "$GDT_HOST $OTHER $GDT_UNSET",
+			opts:    []parse.ExpandOption{parse.WithAllowedEnv("GDT_HOST")},
+			exp:     "example.com $OTHER $GDT_UNSET",
+		},
+		{
+			name:    "allowed prefix",
+			content: "$GDT_HOST ${OTHER}",
+			opts: []parse.ExpandOption{
+				parse.WithAllowedEnvPrefix("GDT_"),
+				parse.WithStrictEnv(true),
+			},
+			exp: "example.com ${OTHER}",
+		},
+		{
+			name:    "lookup",
+			content: "$GDT_HOST",
+			opts: []parse.ExpandOption{
+				parse.WithEnvLookup(func(name string) (string, bool) {
+					return "looked-up-" + name, true
+				}),
+			},
+			exp: "looked-up-GDT_HOST",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := parse.Expand(c.content, c.opts...)
+			if c.expErr != "" {
+				assert.ErrorContains(t, err, c.expErr)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, c.exp, got)
+		})
+	}
+}
+
+func TestExpandDoesNotMutateEnv(t *testing.T) {
+	before := os.Environ()
+	_, err := parse.Expand("$$HOME $HOME")
+	require.Nil(t, err)
+	assert.Equal(t, before, os.Environ())
+}
+
+func TestUnmarshalExpanded(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	t.Setenv("GDT_REPLICAS", "3")
+	t.Setenv("GDT_NAME", "web")
+
+	contents := []byte(`# comments are not expanded: $GDT_UNSET
+name: &name $GDT_NAME
+alias: *name
+replicas: $GDT_REPLICAS
+quoted: "$GDT_REPLICAS"
+script: |
+  echo $GDT_NAME
+`)
+	var got map[string]any
+	err := parse.UnmarshalExpanded(
+		contents, &got, parse.WithStrictEnv(true),
+	)
+	require.Nil(err)
+	assert.Equal(map[string]any{
+		"name":     "web",
+		"alias":    "web",
+		"replicas": 3,
+		"quoted":   "3",
+		"script":   "echo web\n",
+	}, got)
+
+	got = nil
+	err = parse.UnmarshalExpanded(
+		contents, &got, parse.WithoutBlockScalars(),
+	)
+	require.Nil(err)
+	assert.Equal("echo $GDT_NAME\n", got["script"])
+
+	err = parse.UnmarshalExpanded(
+		[]byte("name: test\nhost: $GDT_UNSET\n"),
+		&got,
+		parse.WithStrictEnv(true),
+	)
+	require.NotNil(err)
+	var perr *parse.Error
+	require.ErrorAs(err, &perr)
+	assert.Equal(2, perr.Line)
+	assert.Equal(7, perr.Column)
+	assert.Contains(perr.Message, "GDT_UNSET")
+}
@@ -0,0 +1,78 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package parse
+
+import (
+	"slices"
+	"strings"
+)
+
+// ClosestMatches returns the supplied candidates that are a small number of
+// edits away from the supplied subject, e.g. "exec" for "exce", closest
+// first. Comparisons are case-insensitive.
+func ClosestMatches(subject string, candidates []string) []string {
+	type match struct {
+		name string
+		dist int
+	}
+	lowered := strings.ToLower(subject)
+	limit := max(1, len(subject)/3)
+	matches := []match{}
+	for _, c := range candidates {
+		dist := editDistance(lowered, strings.ToLower(c))
+		if c == subject || dist > limit {
+			continue
+		}
+		if slices.ContainsFunc(matches, func(m match) bool {
+			return m.name == c
+		}) {
+			continue
+		}
+		matches = append(matches, match{name: c, dist: dist})
+	}
+	slices.SortStableFunc(matches, func(a, b match) int {
+		return a.dist - b.dist
+	})
+	res := make([]string, len(matches))
+	for x, m := range matches {
+		res[x] = m.name
+	}
+	return res
+}
+
+// editDistance returns the number of single-character insertions, deletions,
+// substitutions and transpositions of adjacent characters needed to turn a
+// into b.
+func editDistance(a, b string) int {
+	ra := []rune(a)
+	rb := []rune(b)
+	// d[i][j] is the distance between the first i runes of a and the first j
+	// runes of b.
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(
+				d[i-1][j]+1,
+				d[i][j-1]+1,
+				d[i-1][j-1]+cost,
+			)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
@@ -0,0 +1,36 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package parse_test
+
+import (
+	"testing"
+
+	"github.com/gdt-dev/core/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClosestMatches(t *testing.T) {
+	candidates := []string{
+		"name", "description", "timeout", "exec", "shell", "assert",
+	}
+
+	cases := []struct {
+		subject string
+		exp     []string
+	}{
+		{subject: "exce", exp: []string{"exec"}},
+		{subject: "Exec", exp: []string{"exec"}},
+		{subject: "asert", exp: []string{"assert"}},
+		{subject: "tiemout", exp: []string{"timeout"}},
+		{subject: "descriptoin", exp: []string{"description"}},
+		{subject: "exec", exp: []string{}},
+		{subject: "gibber", exp: []string{}},
+	}
+	for _, c := range cases {
+		t.Run(c.subject, func(t *testing.T) {
+			assert.Equal(t, c.exp, parse.ClosestMatches(c.subject, candidates))
+		})
+	}
+}
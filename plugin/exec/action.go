@@ -7,7 +7,10 @@ package exec
 import (
 	"bytes"
 	"context"
+	"io"
+	"os"
 	"os/exec"
+	"slices"
 	"strings"
 
 	"github.com/gdt-dev/core/api"
@@ -37,6 +40,126 @@ type Action struct {
 	VarStderr string `yaml:"var-stderr,omitempty"`
 	// VarRC is a shortcut for Var:{VARIABLE_NAME}:from:returncode
 	VarRC string `yaml:"var-rc,omitempty"`
+	// Stdin is the content supplied to the command's stdin, either inline or
+	// read from a file. If nil (the default), the command has no stdin.
+	Stdin *Stdin `yaml:"stdin,omitempty"`
+	// Env is a map of environment variables to set for the command. Values may
+	// refer to variables saved by prior test specs.
+	Env map[string]string `yaml:"env,omitempty"`
+	// EnvClear indicates that the command should not inherit the environment
+	// of the test process. When true, only the variables in Env are set.
+	EnvClear *bool `yaml:"env-clear,omitempty"`
+	// Workdir is the directory to run the command in. Relative paths are
+	// resolved against the scenario's directory. If empty (the default), the
+	// command is run in the scenario's directory.
+	Workdir string `yaml:"workdir,omitempty"`
+	// MaxOutput is the maximum number of bytes captured from each of the
+	// command's output streams. Output beyond this is discarded and a
+	// truncation marker is appended to the captured output. If zero (the
+	// default), `DefaultMaxOutput` is used.
+	MaxOutput int `yaml:"max-output,omitempty"`
+	// Tee contains paths to files that receive the command's full,
+	// untruncated output.
+	Tee *Tee `yaml:"tee,omitempty"`
+}
+
+// Stdin describes the content supplied to a command's stdin.
+type Stdin struct {
+	// Text is inline content to supply to the command's stdin.
+	Text string `yaml:"text,omitempty"`
+	// File is the path to a file whose contents are supplied to the command's
+	// stdin. Relative paths are resolved against the scenario's directory.
+	File string `yaml:"file,omitempty"`
+}
+
+// withDefaults returns a copy of the Action with any fields that were not set
+// on the Action populated from the supplied exec plugin defaults. Environment
+// variables set on the Action override those of the same name in the
+// defaults.
+func (a *Action) withDefaults(d *execDefaults) *Action {
+	if d == nil {
+		return a
+	}
+	res := *a
+	if res.Stdin == nil {
+		res.Stdin = d.Stdin
+	}
+	if len(d.Env) > 0 {
+		env := make(map[string]string, len(d.Env)+len(a.Env))
+		for k, v := range d.Env {
+			env[k] = v
+		}
+		for k, v := range a.Env {
+			env[k] = v
+		}
+		res.Env = env
+	}
+	if res.EnvClear == nil {
+		res.EnvClear = d.EnvClear
+	}
+	if res.Workdir == "" {
+		res.Workdir = d.Workdir
+	}
+	if res.Shell == "" {
+		res.Shell = d.Shell
+	}
+	if res.MaxOutput == 0 {
+		res.MaxOutput = d.MaxOutput
+	}
+	return &res
+}
+
+// environ returns the environment for the command, or nil if the command
+// should inherit the environment of the test process unchanged.
+func (a *Action) environ(ctx context.Context) ([]string, error) {
+	envClear := a.EnvClear != nil && *a.EnvClear
+	if len(a.Env) == 0 && !envClear {
+		return nil, nil
+	}
+	env := []string{}
+	if !envClear {
+		env = os.Environ()
+	}
+	keys := lo.Keys(a.Env)
+	slices.Sort(keys)
+	for _, k := range keys {
+		val, err := expandVars(ctx, "env "+k, a.Env[k])
+		if err != nil {
+			return nil, err
+		}
+		env = append(env, k+"="+val)
+	}
+	return env, nil
+}
+
+// expandVars returns the supplied value, described by what, with any variable
+// references replaced with their stored variable values. An error is returned
+// if a reference cannot be expanded.
+func expandVars(ctx context.Context, what string, val string) (string, error) {
+	res, err := gdtcontext.Expand(ctx, val)
+	if err != nil {
+		return "", api.VarExpandFailed(val, err)
+	}
+	if res != val {
+		debug.Printf(
+			ctx,
+			"exec: replaced %s: %s -> %s",
+			what, val, res,
+		)
+	}
+	return res, nil
+}
+
+// stdin returns a reader for the command's stdin, or nil if the command has
+// no stdin. Callers are responsible for closing the returned reader.
+func (a *Action) stdin(ctx context.Context) (io.ReadCloser, error) {
+	if a.Stdin == nil {
+		return nil, nil
+	}
+	if a.Stdin.File != "" {
+		return os.Open(gdtcontext.ResolvePath(ctx, a.Stdin.File))
+	}
+	return io.NopCloser(strings.NewReader(a.Stdin.Text)), nil
 }
 
 // Do performs a single command or shell execution returning the corresponding
@@ -49,67 +172,99 @@ func (a *Action) Do(
 	errbuf *bytes.Buffer,
 	exitcode *int,
 ) error {
-	var target string
-	var args []string
-	if a.Shell == "" {
-		// Parse time already validated exec string parses into valid shell
-		// args
-		args, _ = shlex.Split(a.Exec)
-		target = args[0]
-		args = args[1:]
-	} else {
-		target = a.Shell
-		args = []string{"-c", a.Exec}
+	var status exitStatus
+	if err := a.do(ctx, outbuf, errbuf, nil, &status); err != nil {
+		return err
 	}
-
-	origTarget := target
-	target = gdtcontext.ReplaceVariables(ctx, target)
-	if target != origTarget {
-		if origTarget != target {
-			debug.Printf(
-				ctx,
-				"exec: replaced target: %s -> %s",
-				origTarget, target,
-			)
-		}
+	if exitcode != nil {
+		*exitcode = status.code
 	}
-	args = lo.Map(args, func(arg string, _ int) string {
-		origArg := arg
-		arg = gdtcontext.ReplaceVariables(ctx, arg)
-		if origArg != arg {
-			debug.Printf(
-				ctx,
-				"exec: replaced arg: %s -> %s",
-				origArg, arg,
-			)
-		}
-		return arg
-	})
-
-	debug.Printf(ctx, "exec: %s %s", target, args)
-
-	cmd := exec.CommandContext(ctx, target, args...)
+	return nil
+}
 
-	outpipe, err := cmd.StdoutPipe()
+// do performs a single command or shell execution, populating the supplied
+// exitStatus with how the command terminated and returning any runtime error.
+// A command that cannot be found is not a runtime error. The command's stdout
+// and stderr pipes are drained concurrently. The `outbuf` and `errbuf` buffers
+// will be filled with the contents of the command's stdout and stderr pipes
+// respectively and, if not nil, the `combbuf` buffer will be filled with the
+// interleaved contents of both pipes. Each is limited to the Action's maximum
+// output size.
+func (a *Action) do(
+	ctx context.Context,
+	outbuf *bytes.Buffer,
+	errbuf *bytes.Buffer,
+	combbuf *bytes.Buffer,
+	status *exitStatus,
+) error {
+	cmd, stdin, err := a.command(ctx, false)
 	if err != nil {
 		return err
 	}
-	errpipe, err := cmd.StderrPipe()
-	if err != nil {
-		return err
+	status.command = cmd.Path
+	if a.Shell != "" {
+		status.command = a.Exec
+	}
+	if stdin != nil {
+		defer stdin.Close()
+	}
+
+	outcap := newLimitedBuffer(a.MaxOutput)
+	errcap := newLimitedBuffer(a.MaxOutput)
+	outw := []io.Writer{outcap}
+	errw := []io.Writer{errcap}
+	var combcap *limitedBuffer
+	if combbuf != nil {
+		combcap = newLimitedBuffer(a.MaxOutput)
+		outw = append(outw, combcap)
+		errw = append(errw, combcap)
+	}
+	tees := []*errWriter{}
+	if a.Tee != nil {
+		for _, tee := range []struct {
+			path    string
+			writers *[]io.Writer
+		}{
+			{a.Tee.Stdout, &outw},
+			{a.Tee.Stderr, &errw},
+		} {
+			if tee.path == "" {
+				continue
+			}
+			f, err := os.Create(gdtcontext.ResolvePath(ctx, tee.path))
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			ew := &errWriter{w: f}
+			tees = append(tees, ew)
+			*tee.writers = append(*tee.writers, ew)
+		}
 	}
+	// The exec package drains each pipe in its own goroutine when the
+	// command's Stdout and Stderr are not files, so a command filling one
+	// pipe cannot block on us reading the other.
+	cmd.Stdout = io.MultiWriter(outw...)
+	cmd.Stderr = io.MultiWriter(errw...)
 
 	err = cmd.Start()
 	if gdtcontext.TimedOut(ctx, err) {
 		return api.ErrTimeoutExceeded
 	}
 	if err != nil {
+		if isNotFound(err) {
+			debug.Printf(ctx, "exec: command not found: %s", err)
+			status.command = cmd.Path
+			status.code = -1
+			status.notFound = true
+			return nil
+		}
 		return err
 	}
+
+	err = cmd.Wait()
 	if outbuf != nil {
-		if _, err = outbuf.ReadFrom(outpipe); err != nil {
-			debug.Printf(ctx, "exec: error reading from stdout: %s", err)
-		}
+		outbuf.WriteString(outcap.String())
 		if outbuf.Len() > 0 {
 			debug.Printf(
 				ctx, "exec: stdout: %s",
@@ -118,9 +273,7 @@ func (a *Action) Do(
 		}
 	}
 	if errbuf != nil {
-		if _, err = errbuf.ReadFrom(errpipe); err != nil {
-			debug.Printf(ctx, "exec: error reading from stderr: %s", err)
-		}
+		errbuf.WriteString(errcap.String())
 		if errbuf.Len() > 0 {
 			debug.Printf(
 				ctx, "exec: stderr: %s",
@@ -128,15 +281,94 @@ func (a *Action) Do(
 			)
 		}
 	}
-
-	err = cmd.Wait()
-	if gdtcontext.TimedOut(ctx, err) {
+	if combbuf != nil {
+		combbuf.WriteString(combcap.String())
+	}
+	// Only the context's deadline indicates a timeout. The command may well
+	// have been sent SIGKILL by something other than the exec package.
+	if gdtcontext.TimedOut(ctx, nil) {
 		return api.ErrTimeoutExceeded
 	}
-	if err != nil && exitcode != nil {
-		eerr, _ := err.(*exec.ExitError)
-		ec := eerr.ExitCode()
-		*exitcode = ec
+	for _, tee := range tees {
+		if tee.err != nil {
+			return tee.err
+		}
+	}
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			return err
+		}
+	}
+	status.setProcessState(cmd.ProcessState)
+	if a.Shell != "" && status.code == shellNotFoundExitCode {
+		status.notFound = true
 	}
 	return nil
 }
+
+// command returns the command to execute for the Action. Variables in the
+// command and its environment are replaced with their stored values. Unless
+// background is true, the command is killed when the supplied context is
+// done. The returned stdin reader, if not nil, must be closed by the caller
+// once the command has exited.
+func (a *Action) command(
+	ctx context.Context,
+	background bool,
+) (*exec.Cmd, io.ReadCloser, error) {
+	var target string
+	var args []string
+	if a.Shell == "" {
+		// Parse time already validated exec string parses into valid shell
+		// args
+		args, _ = shlex.Split(a.Exec)
+		target = args[0]
+		args = args[1:]
+	} else {
+		target = a.Shell
+		args = []string{"-c", a.Exec}
+	}
+
+	target, err := expandVars(ctx, "target", target)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, arg := range args {
+		args[i], err = expandVars(ctx, "arg", arg)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	debug.Printf(ctx, "exec: %s %s", target, args)
+
+	var cmd *exec.Cmd
+	if background {
+		cmd = exec.Command(target, args...)
+	} else {
+		cmd = exec.CommandContext(ctx, target, args...)
+	}
+	// Run the command from the scenario's directory so that relative paths in
+	// the command resolve the same way they do for the test author.
+	cmd.Dir = gdtcontext.BaseDir(ctx)
+	if a.Workdir != "" {
+		workdir, err := expandVars(ctx, "workdir", a.Workdir)
+		if err != nil {
+			return nil, nil, err
+		}
+		cmd.Dir = gdtcontext.ResolvePath(ctx, workdir)
+		debug.Printf(ctx, "exec: workdir: %s", cmd.Dir)
+	}
+	cmd.Env, err = a.environ(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stdin, err := a.stdin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	return cmd, stdin, nil
+}
@@ -7,11 +7,18 @@ package exec
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"syscall"
 
-	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
 
 	"github.com/gdt-dev/core/api"
+	gdtjson "github.com/gdt-dev/core/assertion/json"
 	gdtcontext "github.com/gdt-dev/core/context"
 	"github.com/gdt-dev/core/debug"
 )
@@ -22,16 +29,122 @@ type Expect struct {
 	// (0) is the universal successful exit code, so you only need to set this
 	// if you expect a non-successful result from executing the command.
 	ExitCode int `yaml:"exit-code,omitempty"`
+	// ExitCodes is a set of acceptable exit codes for the executed command,
+	// specified as a list of integers in the `exit-code` field.
+	ExitCodes []int `yaml:"-"`
+	// ExitCodeRange is a range of acceptable exit codes for the executed
+	// command, specified as an object with `min` and `max` fields in the
+	// `exit-code` field.
+	ExitCodeRange *Range `yaml:"-"`
+	// Signal is the name of the signal, e.g. "SIGSEGV", that is expected to
+	// terminate the executed command.
+	Signal string `yaml:"signal,omitempty"`
+	// CommandNotFound indicates that the command is expected not to be
+	// found. When the command is executed in a shell, a 127 exit code from the
+	// shell indicates the command was not found.
+	CommandNotFound bool `yaml:"command-not-found,omitempty"`
 	// Out has things that are expected in the stdout response
 	Out *PipeExpect `yaml:"out,omitempty"`
 	// Err has things that are expected in the stderr response
 	Err *PipeExpect `yaml:"err,omitempty"`
+	// Combined has things that are expected in the interleaved stdout and
+	// stderr response
+	Combined *PipeExpect `yaml:"combined,omitempty"`
+	// exitCodeSet is true if the exit code was explicitly specified.
+	exitCodeSet bool
+	// signal is the parsed value of Signal.
+	signal syscall.Signal
+}
+
+// exitSet returns true if any assertion about how the command terminates was
+// explicitly specified.
+func (e *Expect) exitSet() bool {
+	return e.exitCodeSet || e.signal != 0 || e.CommandNotFound
+}
+
+// exitCodeOK returns true if the supplied exit code is acceptable.
+func (e *Expect) exitCodeOK(code int) bool {
+	switch {
+	case e.ExitCodes != nil:
+		return slices.Contains(e.ExitCodes, code)
+	case e.ExitCodeRange != nil:
+		return e.ExitCodeRange.check("exit code", code) == nil
+	default:
+		return e.ExitCode == code
+	}
+}
+
+// exitCodeString returns a description of the acceptable exit codes.
+func (e *Expect) exitCodeString() string {
+	switch {
+	case e.ExitCodes != nil:
+		return fmt.Sprintf("one of %v", e.ExitCodes)
+	case e.ExitCodeRange != nil:
+		r := e.ExitCodeRange
+		switch {
+		case r.Min == nil:
+			return fmt.Sprintf("at most %d", *r.Max)
+		case r.Max == nil:
+			return fmt.Sprintf("at least %d", *r.Min)
+		default:
+			return fmt.Sprintf("between %d and %d", *r.Min, *r.Max)
+		}
+	default:
+		return strconv.Itoa(e.ExitCode)
+	}
+}
+
+// withDefaults returns a copy of the Expect with any assertions that were not
+// set on the Expect populated from the supplied default assertions.
+func (e *Expect) withDefaults(d *Expect) *Expect {
+	if d == nil {
+		return e
+	}
+	if e == nil {
+		return d
+	}
+	res := *e
+	if !res.exitSet() {
+		res.ExitCode = d.ExitCode
+		res.ExitCodes = d.ExitCodes
+		res.ExitCodeRange = d.ExitCodeRange
+		res.Signal = d.Signal
+		res.CommandNotFound = d.CommandNotFound
+		res.exitCodeSet = d.exitCodeSet
+		res.signal = d.signal
+	}
+	if res.Out == nil {
+		res.Out = d.Out
+	}
+	if res.Err == nil {
+		res.Err = d.Err
+	}
+	if res.Combined == nil {
+		res.Combined = d.Combined
+	}
+	return &res
+}
+
+// resolvePaths resolves relative file paths in the assertions about the
+// command's pipes against the supplied base directory.
+func (e *Expect) resolvePaths(baseDir string) error {
+	if e == nil {
+		return nil
+	}
+	for _, pe := range []*PipeExpect{e.Out, e.Err, e.Combined} {
+		if err := pe.resolvePaths(baseDir); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // PipeExpect contains assertions about the contents of a pipe
 type PipeExpect struct {
 	// ContainsAll is one or more strings that *all* must be present in the
-	// contents of the pipe
+	// contents of the pipe. The deprecated `is` key is an alias for this
+	// field and therefore does *not* check for an exact match; use Equals for
+	// that.
 	ContainsAll *api.FlexStrings `yaml:"contains,omitempty"`
 	// ContainsNone is one or more strings, *none of which* should be present in
 	// the contents of the pipe
@@ -39,6 +152,68 @@ type PipeExpect struct {
 	// ContainsOneOf is one or more strings of which *at least one* must be
 	// present in the contents of the pipe
 	ContainsAny *api.FlexStrings `yaml:"contains-one-of,omitempty"`
+	// Equals is a string that must exactly equal the contents of the pipe,
+	// ignoring leading and trailing whitespace.
+	Equals *string `yaml:"equals,omitempty"`
+	// Matches is one or more regular expressions that *all* must match the
+	// contents of the pipe. The values of any named capture groups are saved
+	// as variables that can be referred to by subsequent test specs.
+	Matches *api.FlexStrings `yaml:"matches,omitempty"`
+	// StartsWith is a string that the contents of the pipe must start with.
+	StartsWith *string `yaml:"starts-with,omitempty"`
+	// EndsWith is a string that the contents of the pipe must end with.
+	EndsWith *string `yaml:"ends-with,omitempty"`
+	// Lines constrains the number of lines in the contents of the pipe.
+	Lines *Range `yaml:"lines,omitempty"`
+	// Len constrains the length, in bytes, of the contents of the pipe.
+	Len *Range `yaml:"len,omitempty"`
+	// JSON contains assertions about the contents of the pipe, decoded as
+	// JSON.
+	JSON *gdtjson.Expect `yaml:"json,omitempty"`
+	// YAML contains assertions about the contents of the pipe, decoded as
+	// YAML. The decoded document is converted to JSON before the assertions
+	// are evaluated, so JSONPath expressions are used to select elements and
+	// any `len` assertion refers to the length of the converted JSON.
+	YAML *gdtjson.Expect `yaml:"yaml,omitempty"`
+	// matchRegexps contains the compiled regular expressions in Matches.
+	matchRegexps []*regexp.Regexp
+}
+
+// resolvePaths resolves relative file paths in the JSON and YAML assertions
+// about the contents of the pipe against the supplied base directory.
+func (e *PipeExpect) resolvePaths(baseDir string) error {
+	if e == nil {
+		return nil
+	}
+	if err := e.JSON.ResolvePaths(baseDir); err != nil {
+		return err
+	}
+	return e.YAML.ResolvePaths(baseDir)
+}
+
+// Range describes an inclusive range of allowed values for a quantity. A nil
+// Min or Max indicates no lower or upper bound.
+type Range struct {
+	// Min is the minimum allowed value.
+	Min *int `yaml:"min,omitempty"`
+	// Max is the maximum allowed value.
+	Max *int `yaml:"max,omitempty"`
+}
+
+// check returns an error describing how the supplied quantity of a named
+// thing falls outside of the range, or nil if the quantity is in range.
+func (r *Range) check(what string, got int) error {
+	min, max := -1, -1
+	if r.Min != nil {
+		min = *r.Min
+	}
+	if r.Max != nil {
+		max = *r.Max
+	}
+	if (min >= 0 && got < min) || (max >= 0 && got > max) {
+		return api.OutOfRange(what, min, max, got)
+	}
+	return nil
 }
 
 // pipeAssertions contains assertions about the contents of a pipe
@@ -50,6 +225,9 @@ type pipeAssertions struct {
 	name string
 	// failures contains the set of error messages for failed assertions.
 	failures []error
+	// captures contains the values of named capture groups from the
+	// regular expressions in Matches.
+	captures map[string]string
 }
 
 // Fail appends a supplied error to the set of failed assertions
@@ -75,19 +253,10 @@ func (a *pipeAssertions) OK(ctx context.Context) bool {
 	res := true
 	contents := strings.TrimSpace(a.pipe.String())
 	if a.ContainsAll != nil {
-		vals := a.ContainsAll.Values()
-		vals = lo.Map(vals, func(val string, _ int) string {
-			origVal := val
-			val = gdtcontext.ReplaceVariables(ctx, val)
-			if origVal != val {
-				debug.Printf(
-					ctx,
-					"exec.assert.contains: replaced var: %s -> %s",
-					origVal, val,
-				)
-			}
-			return val
-		})
+		vals, ok := a.replaceVars(ctx, "contains", a.ContainsAll.Values())
+		if !ok {
+			return false
+		}
 		for _, find := range vals {
 			if !strings.Contains(contents, find) {
 				a.Fail(api.NotIn(find, a.name))
@@ -97,19 +266,10 @@ func (a *pipeAssertions) OK(ctx context.Context) bool {
 	}
 	if a.ContainsAny != nil {
 		found := false
-		vals := a.ContainsAny.Values()
-		vals = lo.Map(vals, func(val string, _ int) string {
-			origVal := val
-			val = gdtcontext.ReplaceVariables(ctx, val)
-			if origVal != val {
-				debug.Printf(
-					ctx,
-					"exec.assert.contains-any: replaced var: %s -> %s",
-					origVal, val,
-				)
-			}
-			return val
-		})
+		vals, ok := a.replaceVars(ctx, "contains-any", a.ContainsAny.Values())
+		if !ok {
+			return false
+		}
 		for _, find := range vals {
 			if idx := strings.Index(contents, find); idx > -1 {
 				found = true
@@ -122,19 +282,10 @@ func (a *pipeAssertions) OK(ctx context.Context) bool {
 		}
 	}
 	if a.ContainsNone != nil {
-		vals := a.ContainsNone.Values()
-		vals = lo.Map(vals, func(val string, _ int) string {
-			origVal := val
-			val = gdtcontext.ReplaceVariables(ctx, val)
-			if origVal != val {
-				debug.Printf(
-					ctx,
-					"exec.assert.contains-none: replaced var: %s -> %s",
-					origVal, val,
-				)
-			}
-			return val
-		})
+		vals, ok := a.replaceVars(ctx, "contains-none", a.ContainsNone.Values())
+		if !ok {
+			return false
+		}
 		for _, find := range vals {
 			if strings.Contains(contents, find) {
 				a.Fail(api.In(find, a.name))
@@ -142,21 +293,165 @@ func (a *pipeAssertions) OK(ctx context.Context) bool {
 			}
 		}
 	}
+	if a.Equals != nil {
+		vals, ok := a.replaceVars(ctx, "equals", []string{*a.Equals})
+		if !ok {
+			return false
+		}
+		exp := vals[0]
+		if contents != exp {
+			a.Fail(api.NotEqual(exp, contents))
+			res = false
+		}
+	}
+	for _, re := range a.matchRegexps {
+		match := re.FindStringSubmatch(contents)
+		if match == nil {
+			a.Fail(api.NotMatch(re.String(), a.name))
+			res = false
+			continue
+		}
+		for idx, name := range re.SubexpNames() {
+			if name == "" {
+				continue
+			}
+			if a.captures == nil {
+				a.captures = map[string]string{}
+			}
+			a.captures[name] = match[idx]
+		}
+	}
+	if a.StartsWith != nil {
+		vals, ok := a.replaceVars(ctx, "starts-with", []string{*a.StartsWith})
+		if !ok {
+			return false
+		}
+		prefix := vals[0]
+		if !strings.HasPrefix(contents, prefix) {
+			a.Fail(api.NotPrefix(prefix, a.name))
+			res = false
+		}
+	}
+	if a.EndsWith != nil {
+		vals, ok := a.replaceVars(ctx, "ends-with", []string{*a.EndsWith})
+		if !ok {
+			return false
+		}
+		suffix := vals[0]
+		if !strings.HasSuffix(contents, suffix) {
+			a.Fail(api.NotSuffix(suffix, a.name))
+			res = false
+		}
+	}
+	if a.Lines != nil {
+		lines := 0
+		if contents != "" {
+			lines = strings.Count(contents, "\n") + 1
+		}
+		if err := a.Lines.check("lines in "+a.name, lines); err != nil {
+			a.Fail(err)
+			res = false
+		}
+	}
+	if a.Len != nil {
+		if err := a.Len.check("bytes in "+a.name, len(contents)); err != nil {
+			a.Fail(err)
+			res = false
+		}
+	}
+	if a.JSON != nil {
+		ja := gdtjson.New(a.JSON, []byte(contents))
+		if !ja.OK(ctx) {
+			for _, f := range ja.Failures() {
+				a.Fail(f)
+			}
+			res = false
+		}
+	}
+	if a.YAML != nil {
+		converted, err := yamlToJSON([]byte(contents))
+		if err != nil {
+			a.Fail(YAMLUnmarshalError(a.name, err))
+			res = false
+		} else {
+			ja := gdtjson.New(a.YAML, converted)
+			if !ja.OK(ctx) {
+				for _, f := range ja.Failures() {
+					a.Fail(f)
+				}
+				res = false
+			}
+		}
+	}
 	return res
 }
 
+// yamlToJSON decodes the supplied YAML document and returns it encoded as
+// JSON.
+func yamlToJSON(content []byte) ([]byte, error) {
+	var v any
+	if err := yaml.Unmarshal(content, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// Captures returns the values of named capture groups from the pipe's
+// `matches` regular expressions, keyed by capture group name.
+func (a *pipeAssertions) Captures() map[string]string {
+	if a == nil {
+		return nil
+	}
+	return a.captures
+}
+
+// replaceVars returns the supplied values of the named assertion with any
+// variables replaced with their stored variable values. If a variable
+// reference cannot be expanded, the resulting runtime error is recorded and
+// false is returned.
+func (a *pipeAssertions) replaceVars(
+	ctx context.Context,
+	assertion string,
+	vals []string,
+) ([]string, bool) {
+	res := make([]string, 0, len(vals))
+	for _, val := range vals {
+		expanded, err := gdtcontext.Expand(ctx, val)
+		if err != nil {
+			a.Fail(api.VarExpandFailed(val, err))
+			return nil, false
+		}
+		if expanded != val {
+			debug.Printf(
+				ctx,
+				"exec.assert.%s: replaced var: %s -> %s",
+				assertion, val, expanded,
+			)
+		}
+		res = append(res, expanded)
+	}
+	return res, true
+}
+
 // assertions contains all assertions made for the exec test
 type assertions struct {
 	// failures contains the set of error messages for failed assertions
 	failures []error
-	// expExitCode contains the expected exit code
-	expExitCode int
-	// exitCode is the exit code we got from the execution
-	exitCode int
+	// expExit contains the expected exit code(s), signal or command not
+	// found assertions
+	expExit Expect
+	// status describes how the execution terminated
+	status *exitStatus
+	// ignoreExit is true if how the execution terminated should not be
+	// asserted on
+	ignoreExit bool
 	// expOutPipe contains the assertions against stdout
 	expOutPipe *pipeAssertions
 	// expErrPipe contains the assertions against stderr
 	expErrPipe *pipeAssertions
+	// expCombinedPipe contains the assertions against the interleaved stdout
+	// and stderr
+	expCombinedPipe *pipeAssertions
 }
 
 // Fail appends a supplied error to the set of failed assertions
@@ -176,8 +471,7 @@ func (a *assertions) Failures() []error {
 // if all assertions pass.
 func (a *assertions) OK(ctx context.Context) bool {
 	res := true
-	if a.expExitCode != a.exitCode {
-		a.Fail(api.NotEqual(a.expExitCode, a.exitCode))
+	if !a.ignoreExit && !a.exitOK() {
 		res = false
 	}
 	if !a.expOutPipe.OK(ctx) {
@@ -188,27 +482,78 @@ func (a *assertions) OK(ctx context.Context) bool {
 		a.failures = append(a.failures, a.expErrPipe.Failures()...)
 		res = false
 	}
+	if !a.expCombinedPipe.OK(ctx) {
+		a.failures = append(a.failures, a.expCombinedPipe.Failures()...)
+		res = false
+	}
 	return res
 }
 
+// exitOK checks how the execution terminated against the expected exit
+// code(s), signal or command not found assertions and returns true if they
+// pass.
+func (a *assertions) exitOK() bool {
+	exp := a.expExit
+	st := a.status
+	switch {
+	case exp.CommandNotFound:
+		if !st.notFound {
+			a.Fail(CommandFound(st.command, st.String()))
+			return false
+		}
+	case exp.signal != 0:
+		if st.signal != exp.signal {
+			a.Fail(UnexpectedSignal(signalName(exp.signal), st.String()))
+			return false
+		}
+	case st.signal != 0:
+		a.Fail(UnexpectedSignal("", st.String()))
+		return false
+	case !exp.exitCodeOK(st.code):
+		if st.notFound {
+			a.Fail(CommandNotFound(st.command))
+		} else {
+			a.Fail(UnexpectedExitCode(exp.exitCodeString(), st.code))
+		}
+		return false
+	}
+	return true
+}
+
+// captures returns the values of named capture groups from the regular
+// expressions in the stdout, stderr and combined output assertions. Values
+// captured from stderr take precedence over values of the same name captured
+// from stdout, and values captured from the combined output take precedence
+// over both.
+func (a *assertions) captures() map[string]string {
+	captures := map[string]string{}
+	for k, v := range a.expOutPipe.Captures() {
+		captures[k] = v
+	}
+	for k, v := range a.expErrPipe.Captures() {
+		captures[k] = v
+	}
+	for k, v := range a.expCombinedPipe.Captures() {
+		captures[k] = v
+	}
+	return captures
+}
+
 // newAssertions returns an assertions object populated with the supplied exec
 // spec assertions
 func newAssertions(
 	e *Expect,
-	exitCode int,
+	status *exitStatus,
 	outPipe *bytes.Buffer,
 	errPipe *bytes.Buffer,
-) api.Assertions {
-	expExitCode := 0
-	if e != nil {
-		expExitCode = e.ExitCode
-	}
+	combinedPipe *bytes.Buffer,
+) *assertions {
 	a := &assertions{
-		failures:    []error{},
-		expExitCode: expExitCode,
-		exitCode:    exitCode,
+		failures: []error{},
+		status:   status,
 	}
 	if e != nil {
+		a.expExit = *e
 		if e.Out != nil {
 			a.expOutPipe = &pipeAssertions{
 				PipeExpect: *e.Out,
@@ -223,6 +568,13 @@ func newAssertions(
 				pipe:       errPipe,
 			}
 		}
+		if e.Combined != nil {
+			a.expCombinedPipe = &pipeAssertions{
+				PipeExpect: *e.Combined,
+				name:       "combined output",
+				pipe:       combinedPipe,
+			}
+		}
 	}
 	return a
 }
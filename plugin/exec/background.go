@@ -0,0 +1,257 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package exec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gdt-dev/core/api"
+	gdtcontext "github.com/gdt-dev/core/context"
+	"github.com/gdt-dev/core/debug"
+)
+
+const (
+	// backgroundDataKeyPrefix is the prefix of the prior run data key under
+	// which a running background process is stored.
+	backgroundDataKeyPrefix = "exec.background."
+	// backgroundStopGrace is how long we wait for a background process to
+	// exit after sending it SIGTERM before killing it.
+	backgroundStopGrace = 5 * time.Second
+	// backgroundReadyInterval is the interval between readiness checks of a
+	// background process.
+	backgroundReadyInterval = 50 * time.Millisecond
+)
+
+// Background describes a named, long-running process started by an exec test
+// spec. The process keeps running after the test spec completes and is
+// terminated either by a subsequent exec test spec with a `stop` field
+// containing the process' name or when the scenario's cleanups are executed.
+type Background struct {
+	// Name is the name of the background process. It is used to stop the
+	// process and to name the variables containing the process' PID and
+	// output.
+	Name string `yaml:"name"`
+	// Ready contains the conditions the background process must meet before
+	// the test spec that started it succeeds. If nil, the test spec succeeds
+	// as soon as the process has started.
+	Ready *Ready `yaml:"ready,omitempty"`
+}
+
+// Ready describes the conditions a background process must meet in order to
+// be considered ready.
+type Ready struct {
+	// Stdout is a regular expression that must match the process' stdout.
+	Stdout string `yaml:"stdout,omitempty"`
+	// Stderr is a regular expression that must match the process' stderr.
+	Stderr string `yaml:"stderr,omitempty"`
+	// TCP is a `host:port` address that must accept TCP connections.
+	TCP string `yaml:"tcp,omitempty"`
+	// Timeout is the maximum duration to wait for the process to become
+	// ready. The test spec's timeout also applies.
+	Timeout string `yaml:"timeout,omitempty"`
+	// stdoutRegexp is the compiled Stdout regular expression.
+	stdoutRegexp *regexp.Regexp
+	// stderrRegexp is the compiled Stderr regular expression.
+	stderrRegexp *regexp.Regexp
+}
+
+// background is a running background process.
+type background struct {
+	name     string
+	cmd      *exec.Cmd
+	stdin    io.ReadCloser
+	stdout   *limitedBuffer
+	stderr   *limitedBuffer
+	done     chan struct{}
+	stopOnce sync.Once
+	stopErr  error
+}
+
+// exited returns true if the background process has exited.
+func (b *background) exited() bool {
+	select {
+	case <-b.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// exitCode returns the exit code of the background process, or -1 if the
+// process has not exited or was terminated by a signal.
+func (b *background) exitCode() int {
+	if !b.exited() {
+		return -1
+	}
+	return b.cmd.ProcessState.ExitCode()
+}
+
+// stop terminates the background process, sending it SIGTERM and killing it
+// if it has not exited after a grace period. It is safe to call stop more
+// than once.
+func (b *background) stop() error {
+	b.stopOnce.Do(func() {
+		defer func() {
+			if b.stdin != nil {
+				b.stdin.Close()
+			}
+		}()
+		if b.exited() {
+			return
+		}
+		if err := b.cmd.Process.Signal(syscall.SIGTERM); err != nil {
+			// Not all platforms support SIGTERM.
+			_ = b.cmd.Process.Kill()
+		}
+		select {
+		case <-b.done:
+		case <-time.After(backgroundStopGrace):
+			err := b.cmd.Process.Kill()
+			if err != nil && !errors.Is(err, os.ErrProcessDone) {
+				b.stopErr = err
+				return
+			}
+			<-b.done
+		}
+	})
+	return b.stopErr
+}
+
+// ready returns true if the background process meets all of the supplied
+// readiness conditions.
+func (b *background) ready(r *Ready) bool {
+	if r.stdoutRegexp != nil && !r.stdoutRegexp.MatchString(b.stdout.String()) {
+		return false
+	}
+	if r.stderrRegexp != nil && !r.stderrRegexp.MatchString(b.stderr.String()) {
+		return false
+	}
+	if r.TCP != "" {
+		conn, err := net.DialTimeout("tcp", r.TCP, backgroundReadyInterval)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+	}
+	return true
+}
+
+// waitReady waits until the background process meets the supplied readiness
+// conditions, returning an assertion failure if the process exits or the
+// context is done before that happens.
+func (b *background) waitReady(ctx context.Context, r *Ready) error {
+	if r == nil {
+		return nil
+	}
+	if r.Timeout != "" {
+		// Parse time already validated the timeout duration.
+		d, _ := time.ParseDuration(r.Timeout)
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+	ticker := time.NewTicker(backgroundReadyInterval)
+	defer ticker.Stop()
+	for {
+		if b.ready(r) {
+			return nil
+		}
+		select {
+		case <-b.done:
+			if b.ready(r) {
+				return nil
+			}
+			return BackgroundExited(b.name, b.exitCode())
+		case <-ctx.Done():
+			return BackgroundNotReady(b.name)
+		case <-ticker.C:
+		}
+	}
+}
+
+// startBackground starts the Spec's command as a named background process and
+// waits for it to become ready. The returned Result contains the process'
+// PID and output so far as run data and a cleanup function that terminates
+// the process.
+func (s *Spec) startBackground(ctx context.Context) (*api.Result, error) {
+	name := s.Background.Name
+	cmd, stdin, err := s.command(ctx, true)
+	if err != nil {
+		return nil, ExecRuntimeError(err)
+	}
+	b := &background{
+		name:   name,
+		cmd:    cmd,
+		stdin:  stdin,
+		stdout: newLimitedBuffer(s.MaxOutput),
+		stderr: newLimitedBuffer(s.MaxOutput),
+		done:   make(chan struct{}),
+	}
+	cmd.Stdout = b.stdout
+	cmd.Stderr = b.stderr
+	if err := cmd.Start(); err != nil {
+		if stdin != nil {
+			stdin.Close()
+		}
+		return nil, ExecRuntimeError(err)
+	}
+	pid := cmd.Process.Pid
+	debug.Printf(ctx, "exec: background %s started (pid %d)", name, pid)
+	go func() {
+		_ = cmd.Wait()
+		close(b.done)
+	}()
+
+	if err := b.waitReady(ctx, s.Background.Ready); err != nil {
+		if stopErr := b.stop(); stopErr != nil {
+			debug.Printf(ctx, "exec: background %s stop: %s", name, stopErr)
+		}
+		return api.NewResult(api.WithFailures(err)), nil
+	}
+	debug.Printf(ctx, "exec: background %s ready", name)
+
+	res := api.NewResult()
+	res.SetData(backgroundDataKeyPrefix+name, b)
+	res.SetData(name+"_PID", pid)
+	res.SetData(name+"_STDOUT", strings.TrimSpace(b.stdout.String()))
+	res.SetData(name+"_STDERR", strings.TrimSpace(b.stderr.String()))
+	res.AddCleanupWithError(b.stop)
+	return res, nil
+}
+
+// stopBackground terminates the background process named in the Spec's Stop
+// field, filling the supplied buffers with the process' output and setting
+// the supplied exit code.
+func (s *Spec) stopBackground(
+	ctx context.Context,
+	outbuf *bytes.Buffer,
+	errbuf *bytes.Buffer,
+	status *exitStatus,
+) error {
+	b, ok := gdtcontext.PriorRun(ctx)[backgroundDataKeyPrefix+s.Stop].(*background)
+	if !ok {
+		return BackgroundUnknown(s.Stop)
+	}
+	if err := b.stop(); err != nil {
+		return ExecRuntimeError(err)
+	}
+	debug.Printf(ctx, "exec: background %s stopped", s.Stop)
+	outbuf.WriteString(b.stdout.String())
+	errbuf.WriteString(b.stderr.String())
+	status.command = b.name
+	status.setProcessState(b.cmd.ProcessState)
+	return nil
+}
@@ -5,11 +5,150 @@
 package exec
 
 import (
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/gdt-dev/core/api"
 	"github.com/gdt-dev/core/parse"
 	"gopkg.in/yaml.v3"
 )
 
-type execDefaults struct{}
+// execDefaults contains default values for exec test specs that are applied
+// to any exec test spec that does not set the field itself.
+type execDefaults struct {
+	// Stdin is the default content supplied to commands' stdin.
+	Stdin *Stdin `yaml:"stdin,omitempty"`
+	// Env is a map of environment variables to set for commands. Variables in
+	// a test spec's `env` field override variables of the same name here.
+	Env map[string]string `yaml:"env,omitempty"`
+	// EnvClear indicates that commands should not inherit the environment of
+	// the test process.
+	EnvClear *bool `yaml:"env-clear,omitempty"`
+	// Workdir is the default directory to run commands in.
+	Workdir string `yaml:"workdir,omitempty"`
+	// Shell is the default shell to execute commands in.
+	Shell string `yaml:"shell,omitempty"`
+	// MaxOutput is the default maximum number of bytes captured from each of
+	// a command's output streams.
+	MaxOutput int `yaml:"max-output,omitempty"`
+	// Timeout is the default timeout for exec test specs. It overrides the
+	// scenario's default timeout.
+	Timeout *api.Timeout `yaml:"timeout,omitempty"`
+	// Retry is the default retry behaviour for exec test specs. It overrides
+	// the scenario's default retry behaviour.
+	Retry *api.Retry `yaml:"retry,omitempty"`
+	// Assert contains default assertions for exec test specs. Each assertion
+	// field is only applied to test specs that do not set that field.
+	Assert *Expect `yaml:"assert,omitempty"`
+}
+
+func (d *execDefaults) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return parse.ExpectedMapAt(node)
+	}
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		switch key {
+		case "stdin":
+			var si Stdin
+			if err := valNode.Decode(&si); err != nil {
+				return err
+			}
+			d.Stdin = &si
+		case "env":
+			env, err := parseEnv(valNode)
+			if err != nil {
+				return err
+			}
+			d.Env = env
+		case "env-clear", "env_clear":
+			envClear, err := parseBool(valNode)
+			if err != nil {
+				return err
+			}
+			d.EnvClear = &envClear
+		case "workdir", "work-dir", "work_dir":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			d.Workdir = strings.TrimSpace(valNode.Value)
+		case "shell":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			d.Shell = strings.TrimSpace(valNode.Value)
+			if _, err := exec.LookPath(d.Shell); err != nil {
+				return ExecUnknownShell(d.Shell, valNode)
+			}
+		case "max-output", "max_output":
+			maxOutput, err := parseMaxOutput(valNode)
+			if err != nil {
+				return err
+			}
+			d.MaxOutput = maxOutput
+		case "timeout":
+			var to *api.Timeout
+			switch valNode.Kind {
+			case yaml.MappingNode:
+				// We support the old-style timeout:after
+				if err := valNode.Decode(&to); err != nil {
+					return parse.ExpectedTimeoutAt(valNode)
+				}
+			case yaml.ScalarNode:
+				// We also support a straight string duration
+				to = &api.Timeout{
+					After: valNode.Value,
+				}
+			default:
+				return parse.ExpectedScalarOrMapAt(valNode)
+			}
+			if _, err := time.ParseDuration(to.After); err != nil {
+				return parse.ExpectedTimeoutAt(valNode)
+			}
+			d.Timeout = to
+		case "retry":
+			if valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedMapAt(valNode)
+			}
+			var r *api.Retry
+			if err := valNode.Decode(&r); err != nil {
+				return parse.ExpectedRetryAt(valNode)
+			}
+			if r.Attempts != nil {
+				attempts := *r.Attempts
+				if attempts < 1 {
+					return parse.InvalidRetryAttemptsAt(valNode, attempts)
+				}
+			}
+			if r.Interval != "" {
+				if _, err := time.ParseDuration(r.Interval); err != nil {
+					return parse.ExpectedRetryAt(valNode)
+				}
+			}
+			d.Retry = r
+		case "assert":
+			if valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedMapAt(valNode)
+			}
+			var e *Expect
+			if err := valNode.Decode(&e); err != nil {
+				return err
+			}
+			d.Assert = e
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
+		}
+	}
+	return nil
+}
 
 // Defaults is the known exec plugin defaults collection
 type Defaults struct {
@@ -45,3 +184,13 @@ func (d *Defaults) UnmarshalYAML(node *yaml.Node) error {
 	}
 	return nil
 }
+
+// getDefaults returns the exec plugin defaults from the supplied collection of
+// defaults, or nil if there are none.
+func getDefaults(defaults *api.Defaults) *execDefaults {
+	d, ok := defaults.For(pluginName).(*Defaults)
+	if !ok || d == nil {
+		return nil
+	}
+	return &d.execDefaults
+}
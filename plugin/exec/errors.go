@@ -5,12 +5,132 @@
 package exec
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/gdt-dev/core/api"
 )
 
+// ErrYAMLUnmarshal is an ErrFailure when the contents of a pipe cannot be
+// decoded as YAML.
+var ErrYAMLUnmarshal = fmt.Errorf("%w: failed to unmarshal YAML", api.ErrFailure)
+
+// YAMLUnmarshalError returns an ErrYAMLUnmarshal for the named pipe with the
+// supplied decoding error.
+func YAMLUnmarshalError(pipe string, err error) error {
+	return fmt.Errorf("%w in %s: %s", ErrYAMLUnmarshal, pipe, err)
+}
+
+var (
+	// ErrBackgroundNotReady is an ErrFailure when a background process does
+	// not become ready in time.
+	ErrBackgroundNotReady = fmt.Errorf(
+		"%w: background process not ready", api.ErrFailure,
+	)
+	// ErrBackgroundExited is an ErrFailure when a background process exits
+	// before becoming ready.
+	ErrBackgroundExited = fmt.Errorf(
+		"%w: background process exited", api.ErrFailure,
+	)
+	// ErrBackgroundUnknown is a RuntimeError when a test spec refers to a
+	// background process that was not started.
+	ErrBackgroundUnknown = fmt.Errorf(
+		"%w: unknown background process", api.RuntimeError,
+	)
+)
+
+// BackgroundNotReady returns an ErrBackgroundNotReady for the named
+// background process.
+func BackgroundNotReady(name string) error {
+	return fmt.Errorf("%w: %s", ErrBackgroundNotReady, name)
+}
+
+// BackgroundExited returns an ErrBackgroundExited for the named background
+// process with the supplied exit code.
+func BackgroundExited(name string, exitCode int) error {
+	return fmt.Errorf(
+		"%w: %s exited with code %d before becoming ready",
+		ErrBackgroundExited, name, exitCode,
+	)
+}
+
+// BackgroundUnknown returns an ErrBackgroundUnknown for the named background
+// process.
+func BackgroundUnknown(name string) error {
+	return fmt.Errorf("%w: %s", ErrBackgroundUnknown, name)
+}
+
+var (
+	// ErrUnexpectedExitCode is an ErrFailure when a command exits with an
+	// exit code that was not expected.
+	ErrUnexpectedExitCode = fmt.Errorf(
+		"%w: unexpected exit code", api.ErrFailure,
+	)
+	// ErrUnexpectedSignal is an ErrFailure when a command is, or is not,
+	// terminated by a signal contrary to expectations.
+	ErrUnexpectedSignal = fmt.Errorf(
+		"%w: unexpected signal", api.ErrFailure,
+	)
+	// ErrCommandNotFound is an ErrFailure when a command that was expected to
+	// run could not be found.
+	ErrCommandNotFound = fmt.Errorf("%w: command not found", api.ErrFailure)
+	// ErrCommandFound is an ErrFailure when a command that was expected not
+	// to be found was found and executed.
+	ErrCommandFound = fmt.Errorf("%w: command found", api.ErrFailure)
+)
+
+// UnexpectedExitCode returns an ErrUnexpectedExitCode describing the expected
+// exit code(s) and the exit code we got.
+func UnexpectedExitCode(exp string, got int) error {
+	return fmt.Errorf(
+		"%w: expected exit code %s but got %d",
+		ErrUnexpectedExitCode, exp, got,
+	)
+}
+
+// UnexpectedSignal returns an ErrUnexpectedSignal describing the expected
+// signal, if any, and how the command actually terminated.
+func UnexpectedSignal(exp string, got string) error {
+	if exp == "" {
+		return fmt.Errorf("%w: terminated by %s", ErrUnexpectedSignal, got)
+	}
+	return fmt.Errorf(
+		"%w: expected %s but got %s",
+		ErrUnexpectedSignal, exp, got,
+	)
+}
+
+// CommandNotFound returns an ErrCommandNotFound for the named command.
+func CommandNotFound(cmd string) error {
+	return fmt.Errorf("%w: %s", ErrCommandNotFound, cmd)
+}
+
+// CommandFound returns an ErrCommandFound for the named command describing
+// how it terminated.
+func CommandFound(cmd string, got string) error {
+	return fmt.Errorf(
+		"%w: expected %s not to be found but got %s",
+		ErrCommandFound, cmd, got,
+	)
+}
+
+// ErrVarExtract is an ErrFailure when the value of a variable cannot be
+// extracted from its source.
+var ErrVarExtract = fmt.Errorf(
+	"%w: failed to extract variable", api.ErrFailure,
+)
+
+// VarExtractFailed returns an ErrVarExtract for the named variable with the
+// reason the value could not be extracted.
+func VarExtractFailed(name string, err error) error {
+	return fmt.Errorf("%w %s: %s", ErrVarExtract, name, err)
+}
+
 // ExecRuntimeError returns a RuntimeError with an error from the Exec() call.
+// Errors that already are RuntimeErrors are returned unchanged.
 func ExecRuntimeError(err error) error {
+	if errors.Is(err, api.RuntimeError) {
+		return err
+	}
 	return fmt.Errorf("%w: %s", api.RuntimeError, err)
 }
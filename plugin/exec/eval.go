@@ -7,6 +7,7 @@ package exec
 import (
 	"bytes"
 	"context"
+	"errors"
 
 	"github.com/gdt-dev/core/api"
 	"github.com/gdt-dev/core/debug"
@@ -20,32 +21,59 @@ import (
 func (s *Spec) Eval(
 	ctx context.Context,
 ) (*api.Result, error) {
+	if s.Background != nil {
+		return s.startBackground(ctx)
+	}
+
 	outbuf := &bytes.Buffer{}
 	errbuf := &bytes.Buffer{}
+	// We only capture the interleaved output if somebody wants to assert on
+	// it.
+	var combbuf *bytes.Buffer
+	if s.Assert != nil && s.Assert.Combined != nil {
+		combbuf = &bytes.Buffer{}
+	}
 
-	var ec int
+	var status exitStatus
 
-	if err := s.Do(ctx, outbuf, errbuf, &ec); err != nil {
+	expect := s.Assert
+	// A stopped background process is usually terminated by a signal, so we
+	// only assert on how it terminated if asked to.
+	ignoreExit := false
+	if s.Stop != "" {
+		if err := s.stopBackground(ctx, outbuf, errbuf, &status); err != nil {
+			return nil, err
+		}
+		ignoreExit = expect == nil || !expect.exitSet()
+	} else if err := s.do(ctx, outbuf, errbuf, combbuf, &status); err != nil {
 		if err == api.ErrTimeoutExceeded {
 			return api.NewResult(api.WithFailures(api.ErrTimeoutExceeded)), nil
 		}
 		return nil, ExecRuntimeError(err)
 	}
-	a := newAssertions(s.Assert, ec, outbuf, errbuf)
+	a := newAssertions(expect, &status, outbuf, errbuf, combbuf)
+	a.ignoreExit = ignoreExit
+	output := api.WithOutput(outbuf.String() + errbuf.String())
 	if a.OK(ctx) {
-		res := api.NewResult()
-		saveVars(ctx, s.Var, outbuf, errbuf, ec, res)
+		res := api.NewResult(output)
+		failures := saveVars(ctx, s.Var, outbuf, errbuf, status.code, res)
+		if len(failures) > 0 {
+			return api.NewResult(output, api.WithFailures(failures...)), nil
+		}
+		for name, val := range a.captures() {
+			debug.Printf(ctx, "save.vars: %s -> <matches>", name)
+			res.SetData(name, val)
+		}
 		return res, nil
 	}
-	if s.On != nil {
-		if s.On.Fail != nil {
-			outbuf.Reset()
-			errbuf.Reset()
-			err := s.On.Fail.Do(ctx, outbuf, errbuf, nil)
-			if err != nil {
-				debug.Printf(ctx, "error in on.fail.exec: %s", err)
-			}
+	failures := a.Failures()
+	for _, f := range failures {
+		// A variable reference in an assertion that cannot be expanded is
+		// not an assertion failure.
+		if errors.Is(f, api.RuntimeError) {
+			return nil, f
 		}
 	}
-	return api.NewResult(api.WithFailures(a.Failures()...)), nil
+	s.runOnFail(ctx)
+	return api.NewResult(output, api.WithFailures(failures...)), nil
 }
@@ -10,15 +10,22 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
 	"testing"
 
+	"github.com/gdt-dev/core/api"
 	gdtcontext "github.com/gdt-dev/core/context"
 	execplugin "github.com/gdt-dev/core/plugin/exec"
+	"github.com/gdt-dev/core/run"
 	"github.com/gdt-dev/core/scenario"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -113,7 +120,8 @@ func TestExecFailExitCodeNotSpecified(t *testing.T) {
 		ec = 1
 	}
 	msg := fmt.Sprintf(
-		"assertion failed: not equal: expected 0 but got %d", ec,
+		"assertion failed: unexpected exit code: expected exit code 0 but got %d",
+		ec,
 	)
 	require.Contains(debugout, msg)
 }
@@ -156,6 +164,130 @@ func TestIs(t *testing.T) {
 	require.Nil(err)
 }
 
+func TestExact(t *testing.T) {
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "echo-exact.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	require.Nil(err)
+	require.NotNil(s)
+
+	ctx := context.TODO()
+	err = s.Run(ctx, t)
+	require.Nil(err)
+}
+
+func TestFailExecExact(t *testing.T) {
+	if !*failFlag {
+		t.Skip("skipping without -fail flag")
+	}
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "echo-exact-fail.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	require.Nil(err)
+	require.NotNil(s)
+
+	ctx := context.TODO()
+	err = s.Run(ctx, t)
+	require.Nil(err)
+}
+
+func TestExecExact(t *testing.T) {
+	require := require.New(t)
+	target := os.Args[0]
+	failArgs := []string{
+		"-test.v",
+		"-test.run=FailExecExact",
+		"-fail",
+	}
+	outerr, err := exec.Command(target, failArgs...).CombinedOutput()
+
+	// The test should have failed...
+	require.NotNil(err)
+
+	// ... with a precise failure for each assertion
+	out := string(outerr)
+	require.Contains(out, "assertion failed: not equal: expected hello but got hello world")
+	require.Contains(out, "assertion failed: not match: expected stdout to match ^world")
+	require.Contains(out, "assertion failed: not prefix: expected stdout to start with world")
+	require.Contains(out, "assertion failed: not suffix: expected stdout to end with hello")
+	require.Contains(out, "assertion failed: out of range: expected exactly 2 lines in stdout but got 1")
+	require.Contains(out, "assertion failed: out of range: expected at most 5 bytes in stdout but got 11")
+}
+
+func TestJSON(t *testing.T) {
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "echo-json.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	require.Nil(err)
+	require.NotNil(s)
+
+	ctx := context.TODO()
+	err = s.Run(ctx, t)
+	require.Nil(err)
+}
+
+func TestFailExecJSON(t *testing.T) {
+	if !*failFlag {
+		t.Skip("skipping without -fail flag")
+	}
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "echo-json-fail.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	require.Nil(err)
+	require.NotNil(s)
+
+	ctx := context.TODO()
+	err = s.Run(ctx, t)
+	require.Nil(err)
+}
+
+func TestExecJSON(t *testing.T) {
+	require := require.New(t)
+	target := os.Args[0]
+	failArgs := []string{
+		"-test.v",
+		"-test.run=FailExecJSON",
+		"-fail",
+	}
+	outerr, err := exec.Command(target, failArgs...).CombinedOutput()
+
+	// The test should have failed...
+	require.NotNil(err)
+
+	out := string(outerr)
+	require.Contains(out, "JSONPath values not equal")
+	require.Contains(out, "JSON content did not adhere to JSONSchema")
+	require.Contains(out, "failed to unmarshal YAML in stdout")
+}
+
 func TestContains(t *testing.T) {
 	require := require.New(t)
 
@@ -424,6 +556,45 @@ func TestExecOnFail(t *testing.T) {
 	require.Contains(debugout, "echo [bad kitty]")
 }
 
+func TestOnHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping hooks test on Windows")
+	}
+	require := require.New(t)
+	assert := assert.New(t)
+
+	fp := filepath.Join("testdata", "on-hooks.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	require.Nil(err)
+	require.NotNil(s)
+
+	r := run.New()
+	err = s.Run(context.TODO(), r)
+	require.Nil(err)
+	require.False(r.OK())
+
+	results := r.ScenarioResults(fp)
+	require.Len(results, 1)
+	hooks := results[0].Hooks()
+	require.Len(hooks, 2)
+	assert.Equal(api.HookFail, hooks[0].Event)
+	assert.Equal("bad kitty\n", hooks[0].Output)
+	assert.Equal(api.HookAlways, hooks[1].Event)
+	assert.Equal("any kitty\n", hooks[1].Output)
+
+	detail := results[0].Detail()
+	assert.Contains(detail, "on.fail: 0")
+	assert.Contains(detail, "bad kitty")
+	assert.Contains(detail, "any kitty")
+	assert.NotContains(detail, "good kitty")
+}
+
 func TestTimeoutWithWait(t *testing.T) {
 	require := require.New(t)
 
@@ -463,3 +634,385 @@ func TestVar(t *testing.T) {
 	err = s.Run(ctx, t)
 	require.Nil(err)
 }
+
+func TestVarExtract(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping variable extraction test on Windows")
+	}
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "var-extract.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	require.Nil(err)
+	require.NotNil(s)
+
+	ctx := context.TODO()
+	err = s.Run(ctx, t)
+	require.Nil(err)
+}
+
+func TestVarExtractFailures(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping variable extraction test on Windows")
+	}
+	require := require.New(t)
+	assert := assert.New(t)
+
+	fp := filepath.Join("testdata", "var-extract-fail.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	require.Nil(err)
+	require.NotNil(s)
+
+	msgs := []string{
+		"NAME: JSONPath $.name not found",
+		"ID: cannot decode JSON",
+		`PORT: regex port (\d+) did not match`,
+		"SECOND: line 2 out of range (1 lines)",
+	}
+	require.Len(s.Tests, len(msgs))
+
+	ctx := context.TODO()
+	for x, msg := range msgs {
+		res, err := s.Tests[x].Eval(ctx)
+		require.Nil(err)
+		require.Len(res.Failures(), 1)
+		assert.ErrorIs(res.Failures()[0], execplugin.ErrVarExtract)
+		assert.ErrorContains(res.Failures()[0], msg)
+	}
+}
+
+func TestEnvStdinWorkdir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping env, stdin and workdir test on Windows")
+	}
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "env-stdin-workdir.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	require.Nil(err)
+	require.NotNil(s)
+
+	ctx := context.TODO()
+	err = s.Run(ctx, t)
+	require.Nil(err)
+}
+
+func TestExecDefaults(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping exec defaults test on Windows")
+	}
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "exec-defaults.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	require.Nil(err)
+	require.NotNil(s)
+
+	ctx := context.TODO()
+	err = s.Run(ctx, t)
+	require.Nil(err)
+}
+
+func TestOutputCapture(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping output capture test on Windows")
+	}
+	require := require.New(t)
+
+	tee := filepath.Join(t.TempDir(), "stdout")
+	t.Setenv("GDT_TEE", tee)
+
+	fp := filepath.Join("testdata", "output-capture.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	require.Nil(err)
+	require.NotNil(s)
+
+	ctx := context.TODO()
+	err = s.Run(ctx, t)
+	require.Nil(err)
+}
+
+func TestExitStatus(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping exit status test on Windows")
+	}
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "exit-status.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	require.Nil(err)
+	require.NotNil(s)
+
+	ctx := context.TODO()
+	err = s.Run(ctx, t)
+	require.Nil(err)
+}
+
+func TestExitStatusFailures(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping exit status test on Windows")
+	}
+	require := require.New(t)
+	assert := assert.New(t)
+
+	fp := filepath.Join("testdata", "exit-status-fail.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	require.Nil(err)
+	require.NotNil(s)
+
+	tests := []struct {
+		err error
+		msg string
+	}{
+		{
+			execplugin.ErrUnexpectedExitCode,
+			"expected exit code one of [0 1] but got 3",
+		},
+		{
+			execplugin.ErrUnexpectedExitCode,
+			"expected exit code between 2 and 4 but got 5",
+		},
+		{
+			execplugin.ErrUnexpectedSignal,
+			"terminated by SIGTERM",
+		},
+		{
+			execplugin.ErrUnexpectedSignal,
+			"expected SIGSEGV but got exit code 0",
+		},
+		{
+			execplugin.ErrCommandNotFound,
+			"gdt-no-such-command",
+		},
+		{
+			execplugin.ErrCommandFound,
+			"not to be found but got exit code 0",
+		},
+	}
+	require.Len(s.Tests, len(tests))
+
+	ctx := context.TODO()
+	for x, tc := range tests {
+		res, err := s.Tests[x].Eval(ctx)
+		require.Nil(err)
+		require.Len(res.Failures(), 1)
+		assert.ErrorIs(res.Failures()[0], tc.err)
+		assert.ErrorContains(res.Failures()[0], tc.msg)
+	}
+}
+
+func TestBackground(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping background process test on Windows")
+	}
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "background.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	require.Nil(err)
+	require.NotNil(s)
+
+	ctx := context.TODO()
+	err = s.Run(ctx, t)
+	require.Nil(err)
+}
+
+func TestBackgroundCleanup(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping background process test on Windows")
+	}
+	require := require.New(t)
+
+	pidfile := filepath.Join(t.TempDir(), "pid")
+	t.Setenv("GDT_PIDFILE", pidfile)
+
+	fp := filepath.Join("testdata", "background-cleanup.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	require.Nil(err)
+	require.NotNil(s)
+
+	r := run.New()
+	err = s.Run(context.TODO(), r)
+	require.Nil(err)
+	require.True(r.OK())
+
+	// The background process was terminated by the scenario's cleanups.
+	contents, err := os.ReadFile(pidfile)
+	require.Nil(err)
+	pid, err := strconv.Atoi(strings.TrimSpace(string(contents)))
+	require.Nil(err)
+	proc, err := os.FindProcess(pid)
+	require.Nil(err)
+	require.NotNil(proc.Signal(syscall.Signal(0)))
+}
+
+func TestBackgroundTCP(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping background process test on Windows")
+	}
+	require := require.New(t)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(err)
+	defer l.Close()
+	t.Setenv("GDT_TCP_ADDR", l.Addr().String())
+
+	fp := filepath.Join("testdata", "background-tcp.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	require.Nil(err)
+	require.NotNil(s)
+
+	ctx := context.TODO()
+	err = s.Run(ctx, t)
+	require.Nil(err)
+}
+
+func TestBackgroundNotReady(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping background process test on Windows")
+	}
+	require := require.New(t)
+	assert := assert.New(t)
+
+	fp := filepath.Join("testdata", "background-not-ready.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	require.Nil(err)
+	require.NotNil(s)
+	require.Len(s.Tests, 3)
+
+	ctx := context.TODO()
+	res, err := s.Tests[0].Eval(ctx)
+	require.Nil(err)
+	require.Len(res.Failures(), 1)
+	assert.ErrorIs(res.Failures()[0], execplugin.ErrBackgroundNotReady)
+	assert.False(res.HasCleanups())
+
+	res, err = s.Tests[1].Eval(ctx)
+	require.Nil(err)
+	require.Len(res.Failures(), 1)
+	assert.ErrorIs(res.Failures()[0], execplugin.ErrBackgroundExited)
+	assert.ErrorContains(res.Failures()[0], "quitter exited with code 1")
+
+	_, err = s.Tests[2].Eval(ctx)
+	assert.ErrorIs(err, execplugin.ErrBackgroundUnknown)
+	assert.ErrorIs(err, api.RuntimeError)
+}
+
+func TestVarExpandFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping variable expansion test on Windows")
+	}
+	require := require.New(t)
+	assert := assert.New(t)
+
+	fp := filepath.Join("testdata", "var-expand-fail.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	require.Nil(err)
+	require.NotNil(s)
+	require.Len(s.Tests, 4)
+
+	ctx := gdtcontext.SetRun(
+		context.TODO(),
+		map[string]any{"CONFIG": map[string]any{"name": "gdt"}},
+	)
+	for _, spec := range s.Tests {
+		res, err := spec.Eval(ctx)
+		assert.Nil(res)
+		assert.ErrorIs(err, api.ErrVarExpand)
+		assert.ErrorIs(err, api.RuntimeError)
+		assert.ErrorContains(err, "CONFIG.missing")
+	}
+}
+
+func TestDeprecatedOnFail(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping on.fail test on Windows")
+	}
+	require := require.New(t)
+	assert := assert.New(t)
+
+	marker := filepath.Join(t.TempDir(), "on-fail-ran")
+	spec := &execplugin.Spec{
+		Action: execplugin.Action{Exec: "false"},
+		On: &execplugin.On{
+			Fail: &execplugin.Action{Exec: "touch " + marker},
+		},
+	}
+
+	res, err := spec.Eval(context.TODO())
+	require.Nil(err)
+	assert.True(res.Failed())
+	assert.FileExists(marker)
+}
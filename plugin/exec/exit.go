@@ -0,0 +1,103 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package exec
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// shellNotFoundExitCode is the exit code POSIX shells use when a command
+// cannot be found.
+const shellNotFoundExitCode = 127
+
+// signals maps the names of the signals that may be asserted on to their
+// values.
+var signals = map[string]syscall.Signal{
+	"SIGABRT": syscall.SIGABRT,
+	"SIGALRM": syscall.SIGALRM,
+	"SIGBUS":  syscall.SIGBUS,
+	"SIGFPE":  syscall.SIGFPE,
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGILL":  syscall.SIGILL,
+	"SIGINT":  syscall.SIGINT,
+	"SIGKILL": syscall.SIGKILL,
+	"SIGPIPE": syscall.SIGPIPE,
+	"SIGQUIT": syscall.SIGQUIT,
+	"SIGSEGV": syscall.SIGSEGV,
+	"SIGTERM": syscall.SIGTERM,
+	"SIGTRAP": syscall.SIGTRAP,
+}
+
+// parseSignal returns the signal with the supplied name, e.g. "SIGSEGV" or
+// "SEGV", or number, e.g. "11".
+func parseSignal(s string) (syscall.Signal, bool) {
+	if n, err := strconv.Atoi(s); err == nil && n > 0 {
+		return syscall.Signal(n), true
+	}
+	name := strings.ToUpper(s)
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	sig, ok := signals[name]
+	return sig, ok
+}
+
+// signalName returns the name of the supplied signal, e.g. "SIGSEGV".
+func signalName(sig syscall.Signal) string {
+	for name, s := range signals {
+		if s == sig {
+			return name
+		}
+	}
+	return fmt.Sprintf("signal %d", int(sig))
+}
+
+// exitStatus describes how an executed command terminated.
+type exitStatus struct {
+	// command is the name of the executed command.
+	command string
+	// code is the command's exit code, or -1 if the command was terminated
+	// by a signal or could not be started.
+	code int
+	// signal is the signal that terminated the command, or zero if the
+	// command exited normally.
+	signal syscall.Signal
+	// notFound is true if the command could not be found.
+	notFound bool
+}
+
+// String returns a description of how the command terminated.
+func (s *exitStatus) String() string {
+	switch {
+	case s.notFound:
+		return "command not found"
+	case s.signal != 0:
+		return signalName(s.signal)
+	default:
+		return fmt.Sprintf("exit code %d", s.code)
+	}
+}
+
+// setProcessState populates the exitStatus from the supplied state of an
+// exited process.
+func (s *exitStatus) setProcessState(ps *os.ProcessState) {
+	s.code = ps.ExitCode()
+	if ws, ok := ps.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		s.signal = ws.Signal()
+	}
+}
+
+// isNotFound returns true if the supplied error from starting a command
+// indicates the command could not be found.
+func isNotFound(err error) bool {
+	return errors.Is(err, exec.ErrNotFound) || errors.Is(err, fs.ErrNotExist)
+}
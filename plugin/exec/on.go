@@ -4,7 +4,18 @@
 
 package exec
 
+import (
+	"bytes"
+	"context"
+
+	"github.com/gdt-dev/core/debug"
+)
+
 // On describes actions that can be taken upon certain conditions.
+//
+// Deprecated: Use the `on` hooks of the base test spec, `api.Spec.On`, which
+// may contain test specs handled by any plugin. The `on` field in YAML is
+// parsed into those hooks and never into an On.
 type On struct {
 	// Fail contains one or more actions to take if any of a Spec's assertions
 	// fail.
@@ -27,3 +38,16 @@ type On struct {
 	// function to configure additional `io.Writer`s to direct this output to.
 	Fail *Action `yaml:"fail,omitempty"`
 }
+
+// runOnFail executes the Spec's deprecated `On.Fail` action, if any. Errors
+// executing the action are only written to the debug output.
+func (s *Spec) runOnFail(ctx context.Context) {
+	if s.On == nil || s.On.Fail == nil {
+		return
+	}
+	outbuf := &bytes.Buffer{}
+	errbuf := &bytes.Buffer{}
+	if err := s.On.Fail.Do(ctx, outbuf, errbuf, nil); err != nil {
+		debug.Printf(ctx, "error in on.fail.exec: %s", err)
+	}
+}
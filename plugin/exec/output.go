@@ -0,0 +1,92 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package exec
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+)
+
+var (
+	// DefaultMaxOutput is the default maximum number of bytes captured from
+	// each of a command's output streams.
+	DefaultMaxOutput = 10 * 1024 * 1024
+)
+
+// truncatedMarker is appended to captured output that exceeded the maximum
+// capture size.
+const truncatedMarker = "\n[gdt: output truncated, %d bytes discarded]"
+
+// Tee describes files that receive the full, untruncated output of a command.
+type Tee struct {
+	// Stdout is the path to a file that receives the command's stdout.
+	// Relative paths are resolved against the scenario's directory.
+	Stdout string `yaml:"stdout,omitempty"`
+	// Stderr is the path to a file that receives the command's stderr.
+	// Relative paths are resolved against the scenario's directory.
+	Stderr string `yaml:"stderr,omitempty"`
+}
+
+// limitedBuffer is a buffer, safe for concurrent use, that captures at most a
+// maximum number of bytes and discards the rest. Writes never fail so that a
+// command's output is always fully drained.
+type limitedBuffer struct {
+	sync.Mutex
+	buf       bytes.Buffer
+	max       int
+	discarded int
+}
+
+// newLimitedBuffer returns a limitedBuffer capturing at most max bytes. A
+// max of zero or less means DefaultMaxOutput.
+func newLimitedBuffer(max int) *limitedBuffer {
+	if max <= 0 {
+		max = DefaultMaxOutput
+	}
+	return &limitedBuffer{max: max}
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+	remain := b.max - b.buf.Len()
+	if remain >= len(p) {
+		return b.buf.Write(p)
+	}
+	if remain > 0 {
+		b.buf.Write(p[:remain])
+	}
+	b.discarded += len(p) - max(remain, 0)
+	return len(p), nil
+}
+
+// String returns the captured contents, followed by a truncation marker if
+// any bytes were discarded.
+func (b *limitedBuffer) String() string {
+	b.Lock()
+	defer b.Unlock()
+	if b.discarded == 0 {
+		return b.buf.String()
+	}
+	return b.buf.String() + fmt.Sprintf(truncatedMarker, b.discarded)
+}
+
+// errWriter wraps a writer, recording the first error returned from it and
+// discarding all subsequent writes. Writes never fail so that an error
+// writing to, e.g., a tee file does not prevent the remaining writers in an
+// `io.MultiWriter` from receiving output.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (w *errWriter) Write(p []byte) (int, error) {
+	if w.err == nil {
+		_, w.err = w.w.Write(p)
+	}
+	return len(p), nil
+}
@@ -6,15 +6,20 @@ package exec
 
 import (
 	"fmt"
+	"net"
 	"os/exec"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/shlex"
 	"github.com/samber/lo"
+	"github.com/theory/jsonpath"
 	"gopkg.in/yaml.v3"
 
 	"github.com/gdt-dev/core/api"
+	gdtjson "github.com/gdt-dev/core/assertion/json"
 	"github.com/gdt-dev/core/parse"
 )
 
@@ -48,6 +53,117 @@ func ExecUnknownShell(shell string, node *yaml.Node) error {
 	}
 }
 
+// InvalidRegexp returns a parse error indicating the user specified a regular
+// expression that could not be compiled.
+func InvalidRegexp(err error, node *yaml.Node) error {
+	return &parse.Error{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf("invalid regular expression: %s", err),
+	}
+}
+
+// InvalidRange returns a parse error indicating the user specified a range
+// with a minimum greater than its maximum.
+func InvalidRange(node *yaml.Node) error {
+	return &parse.Error{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: "expected range min to be less than or equal to max",
+	}
+}
+
+// InvalidSignal returns a parse error indicating the user specified an
+// unknown signal name.
+func InvalidSignal(node *yaml.Node) error {
+	return &parse.Error{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf("unknown signal %q", node.Value),
+	}
+}
+
+// ExitConflict returns a parse error indicating the user specified more than
+// one of an exit code, signal or command not found assertion.
+func ExitConflict(node *yaml.Node) error {
+	return &parse.Error{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: "expected only one of exit-code, signal or command-not-found",
+	}
+}
+
+// VarSelectorsUnsupported returns a parse error indicating the user specified
+// selectors for a variable whose value is sourced from the return code.
+func VarSelectorsUnsupported(node *yaml.Node) error {
+	return &parse.Error{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: "line, jsonpath, regex and trim are not supported for variables from returncode",
+	}
+}
+
+// StdinConflict returns a parse error indicating the user specified both
+// inline text and a file for a command's stdin.
+func StdinConflict(node *yaml.Node) error {
+	return &parse.Error{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: "expected only one of stdin text or file",
+	}
+}
+
+// BackgroundNameEmpty returns a parse error indicating the user did not
+// specify the name of a background process.
+func BackgroundNameEmpty(node *yaml.Node) error {
+	return &parse.Error{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: "expected non-empty background process name",
+	}
+}
+
+// BackgroundAssertions returns a parse error indicating the user specified
+// assertions or variables on a test spec that starts a background process.
+func BackgroundAssertions(node *yaml.Node) error {
+	return &parse.Error{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: "assert and var are only supported when stopping a background process",
+	}
+}
+
+// InvalidTCPAddress returns a parse error indicating the user specified a TCP
+// address that is not in `host:port` form.
+func InvalidTCPAddress(addr string, node *yaml.Node) error {
+	return &parse.Error{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf("expected host:port TCP address but got %q", addr),
+	}
+}
+
+// StopConflict returns a parse error indicating the user specified a command
+// to execute on a test spec that stops a background process.
+func StopConflict(node *yaml.Node) error {
+	return &parse.Error{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: "expected only one of exec or stop",
+	}
+}
+
+// StopCombined returns a parse error indicating the user specified assertions
+// on combined output for a test spec that stops a background process, whose
+// stdout and stderr are captured separately.
+func StopCombined(node *yaml.Node) error {
+	return &parse.Error{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: "combined output assertions are not supported when stopping a background process",
+	}
+}
+
 func (s *Spec) UnmarshalYAML(node *yaml.Node) error {
 	if node.Kind != yaml.MappingNode {
 		return parse.ExpectedMapAt(node)
@@ -114,6 +230,29 @@ func (s *Spec) UnmarshalYAML(node *yaml.Node) error {
 			if s.Exec == "" {
 				return ExecEmpty(valNode)
 			}
+		case "stdin":
+			var si Stdin
+			if err := valNode.Decode(&si); err != nil {
+				return err
+			}
+			s.Stdin = &si
+		case "env":
+			env, err := parseEnv(valNode)
+			if err != nil {
+				return err
+			}
+			s.Env = env
+		case "env-clear", "env_clear":
+			envClear, err := parseBool(valNode)
+			if err != nil {
+				return err
+			}
+			s.EnvClear = &envClear
+		case "workdir", "work-dir", "work_dir":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			s.Workdir = strings.TrimSpace(valNode.Value)
 		case "assert":
 			if valNode.Kind != yaml.MappingNode {
 				return parse.ExpectedMapAt(valNode)
@@ -123,15 +262,35 @@ func (s *Spec) UnmarshalYAML(node *yaml.Node) error {
 				return err
 			}
 			s.Assert = e
-		case "on":
+		case "max-output", "max_output":
+			maxOutput, err := parseMaxOutput(valNode)
+			if err != nil {
+				return err
+			}
+			s.MaxOutput = maxOutput
+		case "tee":
 			if valNode.Kind != yaml.MappingNode {
 				return parse.ExpectedMapAt(valNode)
 			}
-			var o *On
-			if err := valNode.Decode(&o); err != nil {
+			var tee Tee
+			if err := valNode.Decode(&tee); err != nil {
+				return err
+			}
+			s.Tee = &tee
+		case "background":
+			var b Background
+			if err := valNode.Decode(&b); err != nil {
 				return err
 			}
-			s.On = o
+			s.Background = &b
+		case "stop", "exec-stop", "exec.stop":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			s.Stop = strings.TrimSpace(valNode.Value)
+			if s.Stop == "" {
+				return BackgroundNameEmpty(valNode)
+			}
 		default:
 			if lo.Contains(api.BaseSpecFields, key) {
 				continue
@@ -142,9 +301,21 @@ func (s *Spec) UnmarshalYAML(node *yaml.Node) error {
 	if len(vars) > 0 {
 		s.Var = vars
 	}
+	if s.Stop != "" {
+		if s.Exec != "" || s.Background != nil {
+			return StopConflict(node)
+		}
+		if s.Assert != nil && s.Assert.Combined != nil {
+			return StopCombined(node)
+		}
+		return nil
+	}
 	if s.Exec == "" {
 		return ExecEmpty(node)
 	}
+	if s.Background != nil && (s.Assert != nil || len(s.Var) > 0) {
+		return BackgroundAssertions(node)
+	}
 	if s.Shell != "" {
 		_, err := shlex.Split(s.Exec)
 		if err != nil {
@@ -154,6 +325,272 @@ func (s *Spec) UnmarshalYAML(node *yaml.Node) error {
 	return nil
 }
 
+// UnmarshalYAML accepts either a scalar string, which is the inline content
+// to supply to a command's stdin, or a map with either a `text` or a `file`
+// key.
+func (si *Stdin) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		si.Text = node.Value
+		return nil
+	case yaml.MappingNode:
+	default:
+		return parse.ExpectedMapAt(node)
+	}
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		if valNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(valNode)
+		}
+		switch key {
+		case "text":
+			si.Text = valNode.Value
+		case "file":
+			si.File = strings.TrimSpace(valNode.Value)
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
+		}
+	}
+	if si.Text != "" && si.File != "" {
+		return StdinConflict(node)
+	}
+	return nil
+}
+
+// UnmarshalYAML accepts either a scalar string, which is the name of the
+// background process, or a map with `name` and `ready` keys.
+func (b *Background) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		b.Name = strings.TrimSpace(node.Value)
+		if b.Name == "" {
+			return BackgroundNameEmpty(node)
+		}
+		return nil
+	case yaml.MappingNode:
+	default:
+		return parse.ExpectedScalarOrMapAt(node)
+	}
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		switch key {
+		case "name":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			b.Name = strings.TrimSpace(valNode.Value)
+		case "ready":
+			var r Ready
+			if err := valNode.Decode(&r); err != nil {
+				return err
+			}
+			b.Ready = &r
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
+		}
+	}
+	if b.Name == "" {
+		return BackgroundNameEmpty(node)
+	}
+	return nil
+}
+
+func (r *Ready) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return parse.ExpectedMapAt(node)
+	}
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		if valNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(valNode)
+		}
+		switch key {
+		case "stdout", "out":
+			re, err := regexp.Compile(valNode.Value)
+			if err != nil {
+				return InvalidRegexp(err, valNode)
+			}
+			r.Stdout = valNode.Value
+			r.stdoutRegexp = re
+		case "stderr", "err":
+			re, err := regexp.Compile(valNode.Value)
+			if err != nil {
+				return InvalidRegexp(err, valNode)
+			}
+			r.Stderr = valNode.Value
+			r.stderrRegexp = re
+		case "tcp":
+			addr := strings.TrimSpace(valNode.Value)
+			if _, _, err := net.SplitHostPort(addr); err != nil {
+				return InvalidTCPAddress(addr, valNode)
+			}
+			r.TCP = addr
+		case "timeout":
+			if _, err := time.ParseDuration(valNode.Value); err != nil {
+				return parse.ExpectedTimeoutAt(valNode)
+			}
+			r.Timeout = valNode.Value
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
+		}
+	}
+	return nil
+}
+
+// parseEnv returns the map of environment variables in the supplied YAML
+// node.
+func parseEnv(node *yaml.Node) (map[string]string, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, parse.ExpectedMapAt(node)
+	}
+	env := map[string]string{}
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		valNode := node.Content[i+1]
+		if keyNode.Kind != yaml.ScalarNode {
+			return nil, parse.ExpectedScalarAt(keyNode)
+		}
+		if valNode.Kind != yaml.ScalarNode {
+			return nil, parse.ExpectedScalarAt(valNode)
+		}
+		env[keyNode.Value] = valNode.Value
+	}
+	return env, nil
+}
+
+func (e *VarEntry) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return parse.ExpectedMapAt(node)
+	}
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		if valNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(valNode)
+		}
+		switch key {
+		case "from":
+			e.From = strings.TrimSpace(valNode.Value)
+		case "line":
+			line, err := strconv.Atoi(valNode.Value)
+			if err != nil || line == 0 {
+				return parse.ExpectedIntAt(valNode)
+			}
+			e.Line = &line
+		case "jsonpath", "json-path", "json_path":
+			path := strings.TrimSpace(valNode.Value)
+			if len(path) == 0 || path[0] != '$' {
+				return gdtjson.JSONPathInvalidNoRoot(path, valNode)
+			}
+			p, err := jsonpath.Parse(path)
+			if err != nil {
+				return gdtjson.JSONPathInvalid(path, err, valNode)
+			}
+			e.JSONPath = path
+			e.path = p
+		case "regex", "regexp":
+			re, err := regexp.Compile(valNode.Value)
+			if err != nil {
+				return InvalidRegexp(err, valNode)
+			}
+			e.Regex = valNode.Value
+			e.re = re
+		case "trim":
+			trim, err := parseBool(valNode)
+			if err != nil {
+				return err
+			}
+			e.Trim = &trim
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
+		}
+	}
+	if e.From == varFromRC && e.hasSelectors() {
+		return VarSelectorsUnsupported(node)
+	}
+	return nil
+}
+
+// parseMaxOutput returns the positive maximum output size in the supplied YAML
+// node.
+func parseMaxOutput(node *yaml.Node) (int, error) {
+	if node.Kind != yaml.ScalarNode {
+		return 0, parse.ExpectedScalarAt(node)
+	}
+	v, err := strconv.Atoi(node.Value)
+	if err != nil || v < 1 {
+		return 0, parse.ExpectedIntAt(node)
+	}
+	return v, nil
+}
+
+func (t *Tee) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return parse.ExpectedMapAt(node)
+	}
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		if valNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(valNode)
+		}
+		switch key {
+		case "stdout", "out":
+			t.Stdout = strings.TrimSpace(valNode.Value)
+		case "stderr", "err":
+			t.Stderr = strings.TrimSpace(valNode.Value)
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
+		}
+	}
+	return nil
+}
+
+// parseBool returns the boolean value of the supplied YAML node.
+func parseBool(node *yaml.Node) (bool, error) {
+	if node.Kind != yaml.ScalarNode {
+		return false, parse.ExpectedScalarAt(node)
+	}
+	var b bool
+	if err := node.Decode(&b); err != nil {
+		return false, parse.ExpectedScalarAt(node)
+	}
+	return b, nil
+}
+
 func (e *Expect) UnmarshalYAML(node *yaml.Node) error {
 	if node.Kind != yaml.MappingNode {
 		return parse.ExpectedMapAt(node)
@@ -169,14 +606,52 @@ func (e *Expect) UnmarshalYAML(node *yaml.Node) error {
 		valNode := node.Content[i+1]
 		switch key {
 		case "exit_code", "exit-code":
+			switch valNode.Kind {
+			case yaml.ScalarNode:
+				ec, err := strconv.Atoi(valNode.Value)
+				if err != nil {
+					return parse.ExpectedIntAt(valNode)
+				}
+				e.ExitCode = ec
+			case yaml.SequenceNode:
+				ecs := make([]int, 0, len(valNode.Content))
+				for _, ecNode := range valNode.Content {
+					if ecNode.Kind != yaml.ScalarNode {
+						return parse.ExpectedScalarAt(ecNode)
+					}
+					ec, err := strconv.Atoi(ecNode.Value)
+					if err != nil {
+						return parse.ExpectedIntAt(ecNode)
+					}
+					ecs = append(ecs, ec)
+				}
+				e.ExitCodes = ecs
+			case yaml.MappingNode:
+				var r Range
+				if err := valNode.Decode(&r); err != nil {
+					return err
+				}
+				e.ExitCodeRange = &r
+			default:
+				return parse.ExpectedScalarOrSequenceAt(valNode)
+			}
+			e.exitCodeSet = true
+		case "signal":
 			if valNode.Kind != yaml.ScalarNode {
 				return parse.ExpectedScalarAt(valNode)
 			}
-			ec, err := strconv.Atoi(valNode.Value)
+			sig, ok := parseSignal(strings.TrimSpace(valNode.Value))
+			if !ok {
+				return InvalidSignal(valNode)
+			}
+			e.Signal = valNode.Value
+			e.signal = sig
+		case "command-not-found", "command_not_found", "not-found", "not_found":
+			notFound, err := parseBool(valNode)
 			if err != nil {
 				return err
 			}
-			e.ExitCode = ec
+			e.CommandNotFound = notFound
 		case "out":
 			if valNode.Kind != yaml.MappingNode {
 				return parse.ExpectedMapAt(valNode)
@@ -195,10 +670,28 @@ func (e *Expect) UnmarshalYAML(node *yaml.Node) error {
 				return err
 			}
 			e.Err = pe
+		case "combined", "output":
+			if valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedMapAt(valNode)
+			}
+			var pe *PipeExpect
+			if err := valNode.Decode(&pe); err != nil {
+				return err
+			}
+			e.Combined = pe
 		default:
 			return parse.UnknownFieldAt(key, keyNode)
 		}
 	}
+	exits := 0
+	for _, set := range []bool{e.exitCodeSet, e.signal != 0, e.CommandNotFound} {
+		if set {
+			exits++
+		}
+	}
+	if exits > 1 {
+		return ExitConflict(node)
+	}
 	return nil
 }
 
@@ -243,9 +736,123 @@ func (e *PipeExpect) UnmarshalYAML(node *yaml.Node) error {
 				return err
 			}
 			e.ContainsNone = &v
+		case "equals", "exactly":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			v := valNode.Value
+			e.Equals = &v
+		case "matches", "regex", "regexp":
+			if valNode.Kind != yaml.ScalarNode && valNode.Kind != yaml.SequenceNode {
+				return parse.ExpectedScalarOrSequenceAt(valNode)
+			}
+			var v api.FlexStrings
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			e.matchRegexps = []*regexp.Regexp{}
+			for _, pattern := range v.Values() {
+				re, err := regexp.Compile(pattern)
+				if err != nil {
+					return InvalidRegexp(err, valNode)
+				}
+				e.matchRegexps = append(e.matchRegexps, re)
+			}
+			e.Matches = &v
+		case "starts-with", "starts_with", "prefix":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			v := valNode.Value
+			e.StartsWith = &v
+		case "ends-with", "ends_with", "suffix":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			v := valNode.Value
+			e.EndsWith = &v
+		case "lines", "line-count", "line_count":
+			var r Range
+			if err := valNode.Decode(&r); err != nil {
+				return err
+			}
+			e.Lines = &r
+		case "len", "length":
+			var r Range
+			if err := valNode.Decode(&r); err != nil {
+				return err
+			}
+			e.Len = &r
+		case "json":
+			if valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedMapAt(valNode)
+			}
+			var je gdtjson.Expect
+			if err := valNode.Decode(&je); err != nil {
+				return err
+			}
+			e.JSON = &je
+		case "yaml":
+			if valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedMapAt(valNode)
+			}
+			var ye gdtjson.Expect
+			if err := valNode.Decode(&ye); err != nil {
+				return err
+			}
+			e.YAML = &ye
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
+		}
+	}
+	return nil
+}
+
+// UnmarshalYAML accepts either a scalar integer, which requires the quantity
+// to equal exactly that value, or a map with optional `min` and `max` keys.
+func (r *Range) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		v, err := strconv.Atoi(node.Value)
+		if err != nil || v < 0 {
+			return parse.ExpectedIntAt(node)
+		}
+		r.Min = &v
+		r.Max = &v
+		return nil
+	case yaml.MappingNode:
+	default:
+		return parse.ExpectedMapAt(node)
+	}
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		switch key {
+		case "min", "max":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			v, err := strconv.Atoi(valNode.Value)
+			if err != nil || v < 0 {
+				return parse.ExpectedIntAt(valNode)
+			}
+			if key == "min" {
+				r.Min = &v
+			} else {
+				r.Max = &v
+			}
 		default:
 			return parse.UnknownFieldAt(key, keyNode)
 		}
 	}
+	if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+		return InvalidRange(node)
+	}
 	return nil
 }
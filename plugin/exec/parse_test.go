@@ -109,3 +109,311 @@ func TestParseVar(t *testing.T) {
 	}
 	assert.Equal(expTests, s.Tests)
 }
+
+func TestParseIsAlias(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse-is-alias.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	require.Nil(err)
+	require.NotNil(s)
+	require.Len(s.Tests, 2)
+
+	// The deprecated `is` key is a substring match, not an exact match.
+	spec := s.Tests[0].(*gdtexec.Spec)
+	require.NotNil(spec.Assert)
+	require.NotNil(spec.Assert.Out)
+	require.NotNil(spec.Assert.Out.ContainsAll)
+	assert.Equal([]string{"4"}, spec.Assert.Out.ContainsAll.Values())
+	assert.Nil(spec.Assert.Out.Equals)
+
+	spec = s.Tests[1].(*gdtexec.Spec)
+	require.NotNil(spec.Assert)
+	require.NotNil(spec.Assert.Out)
+	require.NotNil(spec.Assert.Out.Equals)
+	assert.Equal("42", *spec.Assert.Out.Equals)
+	assert.Nil(spec.Assert.Out.ContainsAll)
+}
+
+func TestParseBadVar(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "bad-var.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	assert.ErrorContains(err, "not supported for variables from returncode")
+	assert.Nil(s)
+}
+
+func TestParseBadRegexp(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "bad-regexp.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	assert.ErrorContains(err, "invalid regular expression")
+	assert.Nil(s)
+}
+
+func TestParseBadRange(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "bad-range.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	assert.ErrorContains(err, "expected range min to be less than or equal to max")
+	assert.Nil(s)
+}
+
+func TestParseBadStdin(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "bad-stdin.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	assert.ErrorContains(err, "expected only one of stdin text or file")
+	assert.Nil(s)
+}
+
+func TestParseBadDefaults(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "bad-defaults.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	assert.ErrorIs(err, parse.ErrParseUnknownField)
+	assert.Nil(s)
+}
+
+func TestParseBadMaxOutput(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "bad-max-output.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	assert.ErrorContains(err, "expected int")
+	assert.Nil(s)
+}
+
+func TestParseBadExit(t *testing.T) {
+	tests := []struct {
+		file string
+		msg  string
+	}{
+		{
+			"bad-exit.yaml",
+			"expected only one of exit-code, signal or command-not-found",
+		},
+		{
+			"bad-signal.yaml",
+			`unknown signal "SIGNOPE"`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.file, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			fp := filepath.Join("testdata", tc.file)
+			f, err := os.Open(fp)
+			require.Nil(err)
+
+			s, err := scenario.FromReader(
+				f,
+				scenario.WithPath(fp),
+			)
+			assert.ErrorContains(err, tc.msg)
+			assert.Nil(s)
+		})
+	}
+}
+
+func TestParseExecDefaults(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "exec-defaults.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	require.Nil(err)
+	require.NotNil(s)
+	require.Len(s.Tests, 2)
+
+	first := s.Tests[0].(*gdtexec.Spec)
+	assert.Equal("sh", first.Shell)
+	require.NotNil(first.Timeout())
+	assert.Equal("3s", first.Timeout().After)
+	require.NotNil(first.Retry())
+	require.NotNil(first.Retry().Attempts)
+	assert.Equal(2, *first.Retry().Attempts)
+	require.NotNil(first.Assert)
+	assert.Equal(1, first.Assert.ExitCode)
+	require.NotNil(first.Assert.Out)
+
+	// The test spec's own timeout and exit-code override the defaults.
+	second := s.Tests[1].(*gdtexec.Spec)
+	assert.Equal("sh", second.Shell)
+	assert.Nil(second.Timeout())
+	assert.Equal(0, second.Assert.ExitCode)
+}
+
+func TestParseBadBackground(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "bad-background.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	assert.ErrorContains(err, "assert and var are only supported when stopping a background process")
+	assert.Nil(s)
+}
+
+func TestParseMissingJSONSchema(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "bad-json-schema.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+	)
+	require.NotNil(err)
+	assert.Nil(s)
+
+	var perr *parse.Error
+	require.ErrorAs(err, &perr)
+	assert.Equal(8, perr.Line)
+	assert.Contains(perr.Message, "unable to find JSONSchema file")
+	assert.Contains(perr.Message, filepath.Join("schemas", "missing.json"))
+}
+
+func TestSchemaValidation(t *testing.T) {
+	fps, err := filepath.Glob(filepath.Join("testdata", "*.yaml"))
+	require.Nil(t, err)
+	require.NotEmpty(t, fps)
+
+	for _, fp := range fps {
+		contents, err := os.ReadFile(fp)
+		require.Nil(t, err)
+		if _, err := scenario.FromBytes(
+			contents, scenario.WithPath(fp),
+		); err != nil {
+			// Only scenarios that parse are expected to be valid.
+			continue
+		}
+		t.Run(filepath.Base(fp), func(t *testing.T) {
+			_, err := scenario.FromBytes(
+				contents,
+				scenario.WithPath(fp),
+				scenario.WithSchemaValidation(true),
+			)
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestSchemaValidationFailure(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	contents := []byte(`name: bad-schema
+tests:
+  - exec: echo foo
+    assert:
+      out:
+        is: foo
+      exit-code: zero
+`)
+	s, err := scenario.FromBytes(
+		contents, scenario.WithSchemaValidation(true),
+	)
+	require.NotNil(err)
+	assert.Nil(s)
+
+	var perr *parse.Error
+	require.ErrorAs(err, &perr)
+	assert.Equal(7, perr.Line)
+	assert.Contains(perr.Message, "schema validation failed")
+	assert.Contains(perr.Message, "exit-code")
+}
+
+func TestUnknownSpecSuggestion(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	contents := []byte(`name: typo
+tests:
+  - exec: echo foo
+  - name: misspelled exec field
+    exce: echo foo
+`)
+	s, err := scenario.FromBytes(contents)
+	require.NotNil(err)
+	assert.Nil(s)
+
+	var perr *parse.Error
+	require.ErrorAs(err, &perr)
+	assert.Equal(4, perr.Line)
+	assert.Equal(5, perr.Column)
+	assert.Contains(perr.Message, "no plugin could parse spec definition")
+	assert.Contains(
+		perr.Message,
+		`exec: unknown field: "exce" at line 5, column 5 (did you mean "exec"?)`,
+	)
+}
@@ -0,0 +1,214 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package exec
+
+import (
+	"maps"
+
+	"github.com/gdt-dev/core/api"
+)
+
+// withAliases returns a map of JSON Schema properties in which the supplied
+// schema is keyed by each of the supplied field names.
+func withAliases(schema api.Schema, names ...string) api.Schema {
+	res := api.Schema{}
+	for _, name := range names {
+		res[name] = schema
+	}
+	return res
+}
+
+// properties returns the union of the supplied maps of JSON Schema
+// properties.
+func properties(props ...api.Schema) api.Schema {
+	all := api.Schema{}
+	for _, p := range props {
+		maps.Copy(all, p)
+	}
+	return all
+}
+
+// object returns the JSON Schema of an object with only the properties in the
+// supplied maps of properties.
+func object(props ...api.Schema) api.Schema {
+	return api.Schema{
+		"type":                 "object",
+		"properties":           properties(props...),
+		"additionalProperties": false,
+	}
+}
+
+var (
+	// scalarSchema is the schema of fields that accept any scalar, which is
+	// interpreted as a string.
+	scalarSchema = api.Schema{
+		"type": []any{"string", "number", "boolean", "null"},
+	}
+	boolSchema = api.Schema{"type": "boolean"}
+	intSchema  = api.Schema{"type": "integer"}
+	// flexStringsSchema is the schema of an api.FlexStrings.
+	flexStringsSchema = api.Schema{
+		"oneOf": []any{
+			scalarSchema,
+			api.Schema{"type": "array", "items": scalarSchema},
+		},
+	}
+	// rangeSchema is the schema of a Range.
+	rangeSchema = api.Schema{
+		"oneOf": []any{
+			api.Schema{"type": "integer", "minimum": 0},
+			object(api.Schema{
+				"min": api.Schema{"type": "integer", "minimum": 0},
+				"max": api.Schema{"type": "integer", "minimum": 0},
+			}),
+		},
+	}
+	stdinSchema = api.Schema{
+		"oneOf": []any{
+			scalarSchema,
+			object(api.Schema{"text": scalarSchema, "file": scalarSchema}),
+		},
+	}
+	envSchema = api.Schema{
+		"type":                 "object",
+		"additionalProperties": scalarSchema,
+	}
+	pipeExpectSchema = object(
+		withAliases(
+			flexStringsSchema,
+			"all", "is", "contains", "contains-all", "contains_all",
+		),
+		withAliases(
+			flexStringsSchema,
+			"any", "contains-one-of", "contains-any", "contains_one_of",
+			"contains_any",
+		),
+		withAliases(
+			flexStringsSchema,
+			"none", "none-of", "contains-none-of", "contains-none", "none_of",
+			"contains_none_of", "contains_none",
+		),
+		withAliases(scalarSchema, "equals", "exactly"),
+		withAliases(flexStringsSchema, "matches", "regex", "regexp"),
+		withAliases(scalarSchema, "starts-with", "starts_with", "prefix"),
+		withAliases(scalarSchema, "ends-with", "ends_with", "suffix"),
+		withAliases(rangeSchema, "lines", "line-count", "line_count"),
+		withAliases(rangeSchema, "len", "length"),
+		withAliases(api.Schema{"type": "object"}, "json", "yaml"),
+	)
+	expectSchema = object(
+		withAliases(
+			api.Schema{
+				"oneOf": []any{
+					intSchema,
+					api.Schema{"type": "array", "items": intSchema},
+					object(api.Schema{"min": intSchema, "max": intSchema}),
+				},
+			},
+			"exit_code", "exit-code",
+		),
+		withAliases(
+			api.Schema{"type": []any{"string", "integer"}},
+			"signal",
+		),
+		withAliases(
+			boolSchema,
+			"command-not-found", "command_not_found", "not-found", "not_found",
+		),
+		withAliases(pipeExpectSchema, "out", "err", "combined", "output"),
+	)
+	varEntrySchema = object(
+		api.Schema{
+			"from": api.Schema{
+				"type": "string",
+				"description": "stdout, stderr, returncode or the name " +
+					"of an environment variable",
+			},
+			"line":  intSchema,
+			"regex": scalarSchema,
+			"trim":  boolSchema,
+		},
+		withAliases(scalarSchema, "jsonpath", "json-path", "json_path"),
+		withAliases(scalarSchema, "regexp"),
+	)
+	backgroundSchema = api.Schema{
+		"oneOf": []any{
+			scalarSchema,
+			object(api.Schema{
+				"name": scalarSchema,
+				"ready": object(
+					withAliases(scalarSchema, "stdout", "out"),
+					withAliases(scalarSchema, "stderr", "err"),
+					api.Schema{
+						"tcp":     scalarSchema,
+						"timeout": api.DurationSchema,
+					},
+				),
+			}),
+		},
+	}
+	teeSchema = object(
+		withAliases(scalarSchema, "stdout", "out"),
+		withAliases(scalarSchema, "stderr", "err"),
+	)
+	maxOutputSchema = api.Schema{"type": "integer", "minimum": 1}
+)
+
+// SpecSchema returns the JSON Schema of exec test specs.
+func (p *plugin) SpecSchema() api.Schema {
+	return api.Schema{
+		"properties": properties(
+			api.Schema{
+				"exec":       scalarSchema,
+				"shell":      scalarSchema,
+				"stdin":      stdinSchema,
+				"env":        envSchema,
+				"assert":     expectSchema,
+				"tee":        teeSchema,
+				"background": backgroundSchema,
+				"var": api.Schema{
+					"type":                 "object",
+					"additionalProperties": varEntrySchema,
+				},
+			},
+			withAliases(boolSchema, "env-clear", "env_clear"),
+			withAliases(scalarSchema, "workdir", "work-dir", "work_dir"),
+			withAliases(maxOutputSchema, "max-output", "max_output"),
+			withAliases(scalarSchema, "stop", "exec-stop", "exec.stop"),
+			withAliases(scalarSchema, "var-stdout", "var.stdout", "var_stdout"),
+			withAliases(scalarSchema, "var-stderr", "var.stderr", "var_stderr"),
+			withAliases(
+				scalarSchema,
+				"var-rc", "var.rc", "var_rc", "var-returncode",
+				"var.returncode", "var_returncode",
+			),
+		),
+		"anyOf": []any{
+			api.Schema{"required": []any{"exec"}},
+			api.Schema{"required": []any{"stop"}},
+			api.Schema{"required": []any{"exec-stop"}},
+			api.Schema{"required": []any{"exec.stop"}},
+		},
+	}
+}
+
+// DefaultsSchema returns the JSON Schema of the exec plugin's defaults.
+func (p *plugin) DefaultsSchema() api.Schema {
+	return api.Schema{
+		pluginName: object(
+			api.Schema{
+				"stdin":   stdinSchema,
+				"env":     envSchema,
+				"shell":   scalarSchema,
+				"timeout": api.TimeoutSchema,
+				"retry":   api.RetrySchema,
+				"assert":  expectSchema,
+			},
+			withAliases(boolSchema, "env-clear", "env_clear"),
+			withAliases(scalarSchema, "workdir", "work-dir", "work_dir"),
+			withAliases(maxOutputSchema, "max-output", "max_output"),
+		),
+	}
+}
@@ -15,16 +15,54 @@ type Spec struct {
 	Action
 	// Assert is an object containing the conditions that the Spec will assert.
 	Assert *Expect `yaml:"assert,omitempty"`
+	// Background, if set, starts the command as a named, long-running
+	// background process instead of waiting for the command to complete.
+	Background *Background `yaml:"background,omitempty"`
+	// Stop is the name of a background process, started by a prior test
+	// spec, to terminate. Assertions are evaluated against the background
+	// process' output.
+	Stop string `yaml:"stop,omitempty"`
 	// On is an object containing actions to take upon certain conditions.
-	On *On `yaml:"on,omitempty"`
+	//
+	// Deprecated: Use the `on` hooks of the base test spec, `Spec.Spec.On`,
+	// instead. On is never set when parsing YAML but, when set by Go code, its
+	// Fail action is still executed if any of the Spec's assertions fail.
+	On *On `yaml:"-"`
 	// Var allows the test author to save arbitrary data to the test scenario,
 	// facilitating the passing of variables between test specs potentially
 	// provided by different gdt Plugins.
 	Var Variables `yaml:"var,omitempty"`
+	// timeout is the timeout from the exec plugin defaults, if the Spec does
+	// not have its own timeout.
+	timeout *api.Timeout
+	// retry is the retry behaviour from the exec plugin defaults, if the Spec
+	// does not have its own retry behaviour.
+	retry *api.Retry
 }
 
+// SetBase sets the Spec's base fields and merges any exec plugin defaults
+// into the Spec's action and assertions.
 func (s *Spec) SetBase(b api.Spec) {
 	s.Spec = b
+	d := getDefaults(b.Defaults)
+	if d == nil {
+		return
+	}
+	s.Action = *s.Action.withDefaults(d)
+	s.Assert = s.Assert.withDefaults(d.Assert)
+	if b.Timeout == nil {
+		s.timeout = d.Timeout
+	}
+	if b.Retry == nil {
+		s.retry = d.Retry
+	}
+}
+
+// ResolvePaths resolves relative file paths in the Spec's assertions, e.g.
+// JSONSchema files, against the supplied base directory and returns a parse
+// error if a referenced file does not exist.
+func (s *Spec) ResolvePaths(baseDir string) error {
+	return s.Assert.resolvePaths(baseDir)
 }
 
 func (s *Spec) Base() *api.Spec {
@@ -32,9 +70,9 @@ func (s *Spec) Base() *api.Spec {
 }
 
 func (s *Spec) Retry() *api.Retry {
-	return nil
+	return s.retry
 }
 
 func (s *Spec) Timeout() *api.Timeout {
-	return nil
+	return s.timeout
 }
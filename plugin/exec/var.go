@@ -7,9 +7,14 @@ package exec
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"os"
+	"regexp"
 	"strings"
 
+	"github.com/theory/jsonpath"
+
 	"github.com/gdt-dev/core/api"
 	"github.com/gdt-dev/core/debug"
 )
@@ -27,6 +32,83 @@ type VarEntry struct {
 	// returncode value. All other strings indicate the value of the variable
 	// should be sourced from an envvar of the same name.
 	From string `yaml:"from"`
+	// Line, if set, selects a single line from the source value. Lines are
+	// numbered from 1 and negative numbers count back from the last line, so
+	// -1 selects the last line.
+	Line *int `yaml:"line,omitempty"`
+	// JSONPath, if set, is a JSONPath expression selecting the value from the
+	// source value decoded as JSON. Selected strings are used as-is and any
+	// other selected value is encoded as JSON.
+	JSONPath string `yaml:"jsonpath,omitempty"`
+	// Regex, if set, is a regular expression that must match the source value.
+	// The value of the first capture group is used or, if the regular
+	// expression has no capture groups, the entire match.
+	Regex string `yaml:"regex,omitempty"`
+	// Trim indicates whether leading and trailing whitespace is removed from
+	// the value. Defaults to true.
+	Trim *bool `yaml:"trim,omitempty"`
+	// path is the parsed JSONPath expression.
+	path *jsonpath.Path
+	// re is the compiled regular expression.
+	re *regexp.Regexp
+}
+
+// hasSelectors returns true if the VarEntry selects part of its source value.
+func (e *VarEntry) hasSelectors() bool {
+	return e.Line != nil || e.path != nil || e.re != nil || e.Trim != nil
+}
+
+// extract returns the value selected from the supplied source value, applying
+// the line, JSONPath, regular expression and trim selectors in that order.
+func (e *VarEntry) extract(src string) (string, error) {
+	val := src
+	if e.Line != nil {
+		lines := strings.Split(strings.TrimRight(val, "\n"), "\n")
+		idx := *e.Line - 1
+		if *e.Line < 0 {
+			idx = len(lines) + *e.Line
+		}
+		if idx < 0 || idx >= len(lines) {
+			return "", fmt.Errorf(
+				"line %d out of range (%d lines)", *e.Line, len(lines),
+			)
+		}
+		val = lines[idx]
+	}
+	if e.path != nil {
+		var v any
+		if err := json.Unmarshal([]byte(val), &v); err != nil {
+			return "", fmt.Errorf("cannot decode JSON: %s", err)
+		}
+		nodes := e.path.Select(v)
+		if len(nodes) == 0 {
+			return "", fmt.Errorf("JSONPath %s not found", e.JSONPath)
+		}
+		switch node := nodes[0].(type) {
+		case string:
+			val = node
+		default:
+			b, err := json.Marshal(node)
+			if err != nil {
+				return "", err
+			}
+			val = string(b)
+		}
+	}
+	if e.re != nil {
+		match := e.re.FindStringSubmatch(val)
+		if match == nil {
+			return "", fmt.Errorf("regex %s did not match", e.Regex)
+		}
+		val = match[0]
+		if len(match) > 1 {
+			val = match[1]
+		}
+	}
+	if e.Trim == nil || *e.Trim {
+		val = strings.TrimSpace(val)
+	}
+	return val, nil
 }
 
 // Variables allows the test author to save arbitrary data to the test scenario,
@@ -35,7 +117,8 @@ type VarEntry struct {
 type Variables map[string]VarEntry
 
 // saveVars examines the supplied Variables and what we got back from the
-// Action.Do() call and sets any variables in the run data context key.
+// Action.Do() call and sets any variables in the run data context key. Any
+// variable whose value could not be extracted is returned as a failure.
 func saveVars(
 	ctx context.Context,
 	vars Variables,
@@ -43,22 +126,35 @@ func saveVars(
 	errbuf *bytes.Buffer,
 	ec int,
 	res *api.Result,
-) {
+) []error {
+	failures := []error{}
 	for varName, entry := range vars {
+		var src string
 		switch entry.From {
 		case varFromStdout:
 			debug.Printf(ctx, "save.vars: %s -> <stdout>", varName)
-			res.SetData(varName, strings.TrimSpace(outbuf.String()))
+			src = outbuf.String()
 		case varFromStderr:
 			debug.Printf(ctx, "save.vars: %s -> <stderr>", varName)
-			res.SetData(varName, strings.TrimSpace(errbuf.String()))
+			src = errbuf.String()
 		case varFromRC:
 			debug.Printf(ctx, "save.vars: %s -> <returncode>", varName)
 			res.SetData(varName, ec)
+			continue
 		default:
-			extracted := os.Getenv(entry.From)
-			debug.Printf(ctx, "save.vars: %s -> %s", varName, extracted)
-			res.SetData(varName, extracted)
+			src = os.Getenv(entry.From)
+			if !entry.hasSelectors() {
+				debug.Printf(ctx, "save.vars: %s -> %s", varName, src)
+				res.SetData(varName, src)
+				continue
+			}
+		}
+		val, err := entry.extract(src)
+		if err != nil {
+			failures = append(failures, VarExtractFailed(varName, err))
+			continue
 		}
+		res.SetData(varName, val)
 	}
+	return failures
 }
@@ -5,17 +5,21 @@
 package plugin
 
 import (
+	"slices"
 	"strings"
 	"sync"
 
 	"github.com/gdt-dev/core/api"
 )
 
-// registry stores a set of Plugins and is safe to use in threaded
+// registry stores an ordered set of Plugins and is safe to use in threaded
 // environments.
 type registry struct {
 	sync.RWMutex
 	entries map[string]api.Plugin
+	// order contains the lowercased names of the registered Plugins in the
+	// order they were first registered.
+	order []string
 }
 
 // Remove delists the Plugin with registry. Only really useful for testing.
@@ -24,23 +28,32 @@ func (r *registry) Remove(p api.Plugin) {
 	defer r.Unlock()
 	lowered := strings.ToLower(p.Info().Name)
 	delete(r.entries, lowered)
+	r.order = slices.DeleteFunc(r.order, func(name string) bool {
+		return name == lowered
+	})
 }
 
-// Add registers a Plugin with the registry.
+// Add registers a Plugin with the registry. Registering a Plugin with the
+// same name as an already-registered Plugin replaces that Plugin but keeps
+// its position in the registry's order.
 func (r *registry) Add(p api.Plugin) {
 	r.Lock()
 	defer r.Unlock()
 	lowered := strings.ToLower(p.Info().Name)
+	if _, exists := r.entries[lowered]; !exists {
+		r.order = append(r.order, lowered)
+	}
 	r.entries[lowered] = p
 }
 
-// List returns a slice of Plugins that are registered with gdt.
+// List returns a slice of Plugins that are registered with gdt, in the order
+// they were registered.
 func (r *registry) List() []api.Plugin {
 	r.RLock()
 	defer r.RUnlock()
-	res := []api.Plugin{}
-	for _, p := range r.entries {
-		res = append(res, p)
+	res := make([]api.Plugin, 0, len(r.order))
+	for _, name := range r.order {
+		res = append(res, r.entries[name])
 	}
 	return res
 }
@@ -59,7 +72,8 @@ func Register(p api.Plugin) {
 	knownPlugins.Add(p)
 }
 
-// Registered returns a slice of pointers to gdt's known plugins.
+// Registered returns a slice of pointers to gdt's known plugins, in the order
+// they were registered. Test specs are offered to plugins in this order.
 func Registered() []api.Plugin {
 	return knownPlugins.List()
 }
@@ -51,11 +51,17 @@ func (s *fooSpec) UnmarshalYAML(node *yaml.Node) error {
 	return nil
 }
 
-type fooPlugin struct{}
+type fooPlugin struct {
+	name string
+}
 
 func (p *fooPlugin) Info() api.PluginInfo {
+	name := p.name
+	if name == "" {
+		name = "foo"
+	}
 	return api.PluginInfo{
-		Name: "foo",
+		Name: name,
 	}
 }
 
@@ -87,3 +93,20 @@ func TestRegisterAndList(t *testing.T) {
 	assert.Equal(1, len(plugins))
 	assert.Equal("foo", plugins[0].Info().Name)
 }
+
+func TestRegisteredOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	names := []string{"zed", "alpha", "mid"}
+	for _, name := range names {
+		plugin.Register(&fooPlugin{name: name})
+	}
+	// Registering a plugin again keeps its original position.
+	plugin.Register(&fooPlugin{name: "ALPHA"})
+
+	got := []string{}
+	for _, p := range plugin.Registered() {
+		got = append(got, p.Info().Name)
+	}
+	assert.Equal([]string{"zed", "ALPHA", "mid"}, got[len(got)-3:])
+}
@@ -0,0 +1,79 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/gdt-dev/core/run"
+)
+
+// jsonLinesRecord is the JSON object written for each test unit and for each
+// scenario-level outcome.
+type jsonLinesRecord struct {
+	Level      string   `json:"level"`
+	Scenario   string   `json:"scenario"`
+	Index      *int     `json:"index,omitempty"`
+	Name       string   `json:"name,omitempty"`
+	OK         bool     `json:"ok"`
+	Skipped    bool     `json:"skipped"`
+	SkipReason string   `json:"skip_reason,omitempty"`
+	Elapsed    float64  `json:"elapsed_seconds"`
+	Failures   []string `json:"failures,omitempty"`
+	Errors     []string `json:"errors,omitempty"`
+	Detail     string   `json:"detail,omitempty"`
+}
+
+// jsonLinesReporter writes test run results as JSON lines.
+type jsonLinesReporter struct{}
+
+// JSONLines returns a Reporter that writes test run results as JSON lines,
+// with one JSON object with a `level` of "unit" per test unit. Setup and
+// teardown specs get a JSON object with a `level` of "setup" or "teardown". A
+// scenario that was skipped or had runtime errors gets an additional JSON
+// object with a `level` of "scenario".
+func JSONLines() Reporter {
+	return &jsonLinesReporter{}
+}
+
+// Report writes the results stored in the supplied Run to the supplied writer
+// as JSON lines.
+func (*jsonLinesReporter) Report(w io.Writer, r *run.Run) error {
+	enc := json.NewEncoder(w)
+	for _, sr := range collect(r) {
+		for _, res := range sr.units() {
+			index := res.Index()
+			rec := jsonLinesRecord{
+				Level:    res.level,
+				Scenario: sr.path,
+				Index:    &index,
+				Name:     res.Name(),
+				OK:       res.OK(),
+				Skipped:  res.Skipped(),
+				Elapsed:  res.Elapsed().Seconds(),
+				Failures: failureMessages(res),
+				Detail:   res.Detail(),
+			}
+			if err := enc.Encode(rec); err != nil {
+				return err
+			}
+		}
+		if sr.skipped || len(sr.errors) > 0 {
+			rec := jsonLinesRecord{
+				Level:      levelScenario,
+				Scenario:   sr.path,
+				OK:         len(sr.errors) == 0,
+				Skipped:    sr.skipped,
+				SkipReason: sr.skipReason,
+				Errors:     sr.errors,
+			}
+			if err := enc.Encode(rec); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
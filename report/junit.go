@@ -0,0 +1,149 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gdt-dev/core/run"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// junitReporter writes test run results as JUnit XML.
+type junitReporter struct{}
+
+// JUnit returns a Reporter that writes test run results as JUnit XML, with
+// one `<testsuite>` element per test scenario and one `<testcase>` element per
+// test unit, setup spec and teardown spec. A scenario that was skipped or had
+// runtime errors gets an additional `<testcase>` element, named for the
+// scenario, that carries the `<skipped>` or `<error>` element.
+func JUnit() Reporter {
+	return &junitReporter{}
+}
+
+// Report writes the results stored in the supplied Run to the supplied writer
+// as JUnit XML.
+func (*junitReporter) Report(w io.Writer, r *run.Run) error {
+	doc := junitTestSuites{Name: "gdt"}
+	var total time.Duration
+	for _, sr := range collect(r) {
+		suite := junitTestSuite{Name: sr.path}
+		var elapsed time.Duration
+		for _, res := range sr.units() {
+			tc := junitTestCase{
+				Name:      res.Name(),
+				Classname: sr.path,
+				Time:      seconds(res.Elapsed()),
+				SystemOut: res.Detail(),
+			}
+			switch {
+			case res.Skipped():
+				tc.Skipped = &junitSkipped{}
+				suite.Skipped++
+			case !res.OK():
+				msgs := failureMessages(res)
+				tc.Failure = &junitFailure{
+					Message: msgs[0],
+					Type:    "failure",
+					Text:    strings.Join(msgs, "\n"),
+				}
+				suite.Failures++
+			}
+			suite.Tests++
+			suite.Cases = append(suite.Cases, tc)
+			elapsed += res.Elapsed()
+		}
+		if sr.skipped || len(sr.errors) > 0 {
+			tc := junitTestCase{
+				Name:      sr.path,
+				Classname: sr.path,
+				Time:      seconds(0),
+			}
+			if sr.skipped {
+				tc.Skipped = &junitSkipped{Message: sr.skipReason}
+				suite.Skipped++
+			}
+			if len(sr.errors) > 0 {
+				tc.Error = &junitFailure{
+					Message: sr.errors[0],
+					Type:    "error",
+					Text:    strings.Join(sr.errors, "\n"),
+				}
+				suite.Errors++
+			}
+			suite.Tests++
+			suite.Cases = append(suite.Cases, tc)
+		}
+		suite.Time = seconds(elapsed)
+		doc.Tests += suite.Tests
+		doc.Failures += suite.Failures
+		doc.Errors += suite.Errors
+		doc.Skipped += suite.Skipped
+		total += elapsed
+		doc.Suites = append(doc.Suites, suite)
+	}
+	doc.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// seconds returns the supplied duration formatted as a number of seconds with
+// millisecond precision.
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
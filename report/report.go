@@ -0,0 +1,145 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+// Package report contains reporters that export the results of a test run
+// executed with the `gdt` CLI tool in a number of formats.
+package report
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+
+	"github.com/gdt-dev/core/run"
+)
+
+var (
+	// ErrUnknownReporter is returned from ByName when there is no reporter
+	// with the supplied name.
+	ErrUnknownReporter = errors.New("unknown reporter")
+)
+
+// Reporter writes the results of a test run to a writer in a particular
+// format.
+type Reporter interface {
+	// Report writes the results stored in the supplied Run to the supplied
+	// writer.
+	Report(io.Writer, *run.Run) error
+}
+
+var (
+	// reporters is the set of known reporters, keyed by name.
+	reporters = map[string]func() Reporter{
+		"junit": JUnit,
+		"json":  JSONLines,
+		"tap":   TAP,
+		"tree":  Tree,
+	}
+)
+
+// Names returns the sorted names of the known reporters.
+func Names() []string {
+	names := lo.Keys(reporters)
+	slices.Sort(names)
+	return names
+}
+
+// ByName returns the reporter with the supplied name. Names are
+// case-insensitive. Returns ErrUnknownReporter if there is no reporter with
+// the supplied name.
+func ByName(name string) (Reporter, error) {
+	ctor, found := reporters[strings.ToLower(name)]
+	if !found {
+		return nil, fmt.Errorf(
+			"%w: %q. expected one of %s",
+			ErrUnknownReporter, name, strings.Join(Names(), ", "),
+		)
+	}
+	return ctor(), nil
+}
+
+const (
+	levelSetup    = "setup"
+	levelUnit     = "unit"
+	levelTeardown = "teardown"
+	levelScenario = "scenario"
+)
+
+// unitResult is the result of a test unit, setup spec or teardown spec.
+type unitResult struct {
+	run.TestUnitResult
+	// level is one of levelSetup, levelUnit or levelTeardown.
+	level string
+}
+
+// scenarioResults is the collection of test unit results and scenario-level
+// outcomes for a single test scenario.
+type scenarioResults struct {
+	path    string
+	results []run.TestUnitResult
+	// setup contains the results of the scenario's setup specs.
+	setup []run.TestUnitResult
+	// teardown contains the results of the scenario's teardown specs.
+	teardown []run.TestUnitResult
+	// skipped is true if the scenario itself was skipped.
+	skipped bool
+	// skipReason is the reason the scenario itself was skipped.
+	skipReason string
+	// errors contains the runtime errors that occurred while executing the
+	// scenario.
+	errors []string
+}
+
+// collect returns the test unit results and scenario-level outcomes in the
+// supplied Run, grouped by scenario path and ordered by scenario path.
+func collect(r *run.Run) []scenarioResults {
+	return lo.Map(r.ScenarioPaths(), func(path string, _ int) scenarioResults {
+		reason, skipped := r.ScenarioSkip(path)
+		return scenarioResults{
+			path:       path,
+			results:    r.ScenarioResults(path),
+			setup:      r.ScenarioSetupResults(path),
+			teardown:   r.ScenarioTeardownResults(path),
+			skipped:    skipped,
+			skipReason: reason,
+			errors: lo.Map(
+				r.ScenarioErrors(path),
+				func(err error, _ int) string {
+					return err.Error()
+				},
+			),
+		}
+	})
+}
+
+// units returns the results of the scenario's setup specs, test units and
+// teardown specs, in the order they were executed.
+func (sr scenarioResults) units() []unitResult {
+	units := make(
+		[]unitResult, 0,
+		len(sr.setup)+len(sr.results)+len(sr.teardown),
+	)
+	for _, res := range sr.setup {
+		units = append(units, unitResult{res, levelSetup})
+	}
+	for _, res := range sr.results {
+		units = append(units, unitResult{res, levelUnit})
+	}
+	for _, res := range sr.teardown {
+		units = append(units, unitResult{res, levelTeardown})
+	}
+	return units
+}
+
+// failureMessages returns the string messages of the supplied test unit's
+// failures.
+func failureMessages(res unitResult) []string {
+	return lo.Map(res.Failures(), func(err error, _ int) string {
+		return err.Error()
+	})
+}
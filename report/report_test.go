@@ -0,0 +1,201 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package report_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gdt-dev/core/api"
+	_ "github.com/gdt-dev/core/plugin/exec"
+	"github.com/gdt-dev/core/report"
+	"github.com/gdt-dev/core/run"
+	"github.com/gdt-dev/core/scenario"
+)
+
+// mixedRun returns the Run resulting from executing the testdata/mixed.yaml
+// scenario, which has two passing and one failing test spec.
+func mixedRun(t *testing.T) *run.Run {
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "mixed.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close()
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.Nil(err)
+
+	r := run.New()
+	err = s.Run(context.TODO(), r)
+	require.Nil(err)
+	return r
+}
+
+func TestByName(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, name := range []string{"junit", "json", "tap", "tree", "JUnit"} {
+		rep, err := report.ByName(name)
+		assert.Nil(err)
+		assert.NotNil(rep)
+	}
+
+	rep, err := report.ByName("csv")
+	assert.ErrorIs(err, report.ErrUnknownReporter)
+	assert.Nil(rep)
+}
+
+func TestJUnit(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	r := mixedRun(t)
+	var buf bytes.Buffer
+	err := report.JUnit().Report(&buf, r)
+	require.Nil(err)
+
+	var doc struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Suites   []struct {
+			Name  string `xml:"name,attr"`
+			Cases []struct {
+				Name    string `xml:"name,attr"`
+				Time    string `xml:"time,attr"`
+				Failure *struct {
+					Message string `xml:"message,attr"`
+				} `xml:"failure"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	err = xml.Unmarshal(buf.Bytes(), &doc)
+	require.Nil(err)
+
+	assert.Equal(3, doc.Tests)
+	assert.Equal(1, doc.Failures)
+	require.Len(doc.Suites, 1)
+	suite := doc.Suites[0]
+	assert.Equal(filepath.Join("testdata", "mixed.yaml"), suite.Name)
+	require.Len(suite.Cases, 3)
+	assert.Nil(suite.Cases[0].Failure)
+	require.NotNil(suite.Cases[1].Failure)
+	assert.Contains(suite.Cases[1].Failure.Message, "assertion failed")
+	assert.Nil(suite.Cases[2].Failure)
+	assert.NotEmpty(suite.Cases[0].Time)
+}
+
+func TestJSONLines(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	r := mixedRun(t)
+	var buf bytes.Buffer
+	err := report.JSONLines().Report(&buf, r)
+	require.Nil(err)
+
+	type record struct {
+		Scenario string   `json:"scenario"`
+		Index    int      `json:"index"`
+		Name     string   `json:"name"`
+		OK       bool     `json:"ok"`
+		Skipped  bool     `json:"skipped"`
+		Failures []string `json:"failures"`
+	}
+	records := []record{}
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		var rec record
+		require.Nil(json.Unmarshal(sc.Bytes(), &rec))
+		records = append(records, rec)
+	}
+	require.Len(records, 3)
+	assert.True(records[0].OK)
+	assert.False(records[1].OK)
+	assert.Equal(1, records[1].Index)
+	assert.Len(records[1].Failures, 1)
+	assert.True(records[2].OK)
+	assert.Equal(filepath.Join("testdata", "mixed.yaml"), records[2].Scenario)
+}
+
+func TestTAP(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	r := mixedRun(t)
+	var buf bytes.Buffer
+	err := report.TAP().Report(&buf, r)
+	require.Nil(err)
+
+	out := buf.String()
+	assert.Contains(out, "TAP version 13\n1..3\n")
+	assert.Contains(out, "ok 1 - mixed/passes\n")
+	assert.Contains(out, "not ok 2 - mixed/fails\n")
+	assert.Contains(out, "ok 3 - mixed/passes-again\n")
+}
+
+func TestTree(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	r := mixedRun(t)
+	var buf bytes.Buffer
+	err := report.Tree().Report(&buf, r)
+	require.Nil(err)
+
+	out := buf.String()
+	assert.Contains(out, "  PASS mixed/passes (")
+	assert.Contains(out, "  FAIL mixed/fails (")
+	assert.Contains(out, "2 passed, 1 failed, 0 skipped")
+}
+
+func TestScenarioLevelOutcomes(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	r := run.New()
+	r.StoreSkip("skipped.yaml", "skip-if: exec passed. skipping test.")
+	r.StoreError("errored.yaml", api.FixtureStartFailed("db", errors.New("boom")))
+
+	var buf bytes.Buffer
+	err := report.JUnit().Report(&buf, r)
+	require.Nil(err)
+	out := buf.String()
+	assert.Contains(out, `tests="2" failures="0" errors="1" skipped="1"`)
+	assert.Contains(out, `<skipped message="skip-if: exec passed. skipping test."></skipped>`)
+	assert.Contains(out, `<error message="runtime error: fixture failed to start: db: boom" type="error">`)
+
+	buf.Reset()
+	err = report.JSONLines().Report(&buf, r)
+	require.Nil(err)
+	out = buf.String()
+	assert.Contains(out, `{"level":"scenario","scenario":"errored.yaml","ok":false,"skipped":false,`)
+	assert.Contains(out, `{"level":"scenario","scenario":"skipped.yaml","ok":true,"skipped":true,`)
+
+	buf.Reset()
+	err = report.TAP().Report(&buf, r)
+	require.Nil(err)
+	out = buf.String()
+	assert.Contains(out, "1..2\n")
+	assert.Contains(out, "not ok 1 - errored.yaml\n")
+	assert.Contains(out, "ok 2 - skipped.yaml # SKIP skip-if: exec passed. skipping test.\n")
+
+	buf.Reset()
+	err = report.Tree().Report(&buf, r)
+	require.Nil(err)
+	out = buf.String()
+	assert.Contains(out, "errored.yaml\n  ERROR runtime error: fixture failed to start: db: boom\n")
+	assert.Contains(out, "skipped.yaml\n  SKIP skip-if: exec passed. skipping test.\n")
+}
@@ -0,0 +1,93 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gdt-dev/core/run"
+)
+
+// tapReporter writes test run results in the Test Anything Protocol format.
+type tapReporter struct{}
+
+// TAP returns a Reporter that writes test run results in the Test Anything
+// Protocol (TAP) version 13 format, with one test point per test unit, setup
+// spec and teardown spec. A scenario that was skipped or had runtime errors
+// gets an additional test point named for the scenario. Failure messages and
+// the test unit's captured log are written as a YAML diagnostic block
+// following the test point.
+func TAP() Reporter {
+	return &tapReporter{}
+}
+
+// Report writes the results stored in the supplied Run to the supplied writer
+// in the TAP format.
+func (*tapReporter) Report(w io.Writer, r *run.Run) error {
+	all := collect(r)
+	count := 0
+	for _, sr := range all {
+		count += len(sr.units())
+		if sr.skipped || len(sr.errors) > 0 {
+			count++
+		}
+	}
+	b := &strings.Builder{}
+	b.WriteString("TAP version 13\n")
+	fmt.Fprintf(b, "1..%d\n", count)
+	num := 0
+	for _, sr := range all {
+		for _, res := range sr.units() {
+			num++
+			switch {
+			case res.Skipped():
+				fmt.Fprintf(b, "ok %d - %s # SKIP\n", num, res.Name())
+			case res.OK():
+				fmt.Fprintf(b, "ok %d - %s\n", num, res.Name())
+			default:
+				fmt.Fprintf(b, "not ok %d - %s\n", num, res.Name())
+				b.WriteString("  ---\n")
+				fmt.Fprintf(b, "  scenario: %q\n", sr.path)
+				b.WriteString("  failures:\n")
+				for _, msg := range failureMessages(res) {
+					fmt.Fprintf(b, "    - %q\n", msg)
+				}
+				if detail := res.Detail(); detail != "" {
+					b.WriteString("  detail: |\n")
+					writeIndented(b, detail, "    ")
+				}
+				b.WriteString("  ...\n")
+			}
+		}
+		switch {
+		case len(sr.errors) > 0:
+			num++
+			fmt.Fprintf(b, "not ok %d - %s\n", num, sr.path)
+			b.WriteString("  ---\n")
+			b.WriteString("  errors:\n")
+			for _, msg := range sr.errors {
+				fmt.Fprintf(b, "    - %q\n", msg)
+			}
+			b.WriteString("  ...\n")
+		case sr.skipped:
+			num++
+			fmt.Fprintf(b, "ok %d - %s # SKIP %s\n", num, sr.path, sr.skipReason)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeIndented writes each line of the supplied text to the supplied builder
+// prefixed with the supplied indent.
+func writeIndented(b *strings.Builder, text string, indent string) {
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		b.WriteString(indent)
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+}
@@ -0,0 +1,67 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gdt-dev/core/run"
+)
+
+// treeReporter writes test run results as a human-readable tree.
+type treeReporter struct{}
+
+// Tree returns a Reporter that writes test run results as a human-readable
+// tree of test scenarios and their setup specs, test units and teardown specs,
+// followed by a line summarizing the run. The failure messages and captured
+// log of failed test units are included beneath them.
+func Tree() Reporter {
+	return &treeReporter{}
+}
+
+// Report writes the results stored in the supplied Run to the supplied writer
+// as a human-readable tree.
+func (*treeReporter) Report(w io.Writer, r *run.Run) error {
+	b := &strings.Builder{}
+	for _, sr := range collect(r) {
+		b.WriteString(sr.path)
+		b.WriteString("\n")
+		if sr.skipped {
+			fmt.Fprintf(b, "  SKIP %s\n", sr.skipReason)
+		}
+		for _, msg := range sr.errors {
+			fmt.Fprintf(b, "  ERROR %s\n", msg)
+		}
+		for _, res := range sr.units() {
+			status := "PASS"
+			switch {
+			case res.Skipped():
+				status = "SKIP"
+			case !res.OK():
+				status = "FAIL"
+			}
+			fmt.Fprintf(
+				b, "  %s %s (%s)\n",
+				status, res.Name(), res.Elapsed().Round(time.Millisecond),
+			)
+			if res.OK() {
+				continue
+			}
+			for _, msg := range failureMessages(res) {
+				writeIndented(b, msg, "       ")
+			}
+			if detail := res.Detail(); detail != "" {
+				writeIndented(b, detail, "       ")
+			}
+		}
+	}
+	b.WriteString(r.Summary().String())
+	b.WriteString("\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
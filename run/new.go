@@ -4,12 +4,19 @@
 
 package run
 
+import "time"
+
 type Option func(*Run)
 
 // New returns a new Run object that stores test run state.
 func New(opts ...Option) *Run {
 	r := &Run{
 		scenarioResults: map[string][]TestUnitResult{},
+		setupResults:    map[string][]TestUnitResult{},
+		teardownResults: map[string][]TestUnitResult{},
+		scenarioErrors:  map[string][]error{},
+		scenarioSkips:   map[string]string{},
+		started:         time.Now(),
 	}
 	for _, opt := range opts {
 		opt(r)
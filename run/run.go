@@ -2,6 +2,7 @@ package run
 
 import (
 	"slices"
+	"sync"
 	"time"
 
 	"github.com/gdt-dev/core/api"
@@ -10,37 +11,128 @@ import (
 )
 
 // Run stores state of a test run when tests are executed with the `gdt` CLI
-// tool.
+// tool. Run is safe to use from multiple goroutines, which allows a suite to
+// execute its scenarios in parallel.
 type Run struct {
+	sync.RWMutex
 	// scenarioResults is a map, keyed by the Scenario path, of slices of
 	// TestUnitResult structs corresponding to the test specs in the scenario.
 	// There is guaranteed to be exactly the same number of TestUnitResults in
 	// the slice as scenarios in the scenario.
 	scenarioResults map[string][]TestUnitResult
+	// setupResults is a map, keyed by the Scenario path, of slices of
+	// TestUnitResult structs corresponding to the scenario's setup specs.
+	setupResults map[string][]TestUnitResult
+	// teardownResults is a map, keyed by the Scenario path, of slices of
+	// TestUnitResult structs corresponding to the scenario's teardown specs.
+	teardownResults map[string][]TestUnitResult
+	// scenarioErrors is a map, keyed by the Scenario path, of the runtime
+	// errors that occurred while executing the scenario.
+	scenarioErrors map[string][]error
+	// scenarioSkips is a map, keyed by the Scenario path, of the reason the
+	// entire scenario was skipped.
+	scenarioSkips map[string]string
+	// started is the wall-clock time the Run was created.
+	started time.Time
+	// finished is the wall-clock time the last result, runtime error or skip
+	// was stored to the Run.
+	finished time.Time
 }
 
-// OK returns true if all Scenarios in the Run had all successful test units.
+// OK returns true if no test unit, setup spec or teardown spec in any Scenario
+// in the Run failed and no runtime errors occurred. Skipped test units do not
+// affect the outcome.
 func (r *Run) OK() bool {
-	return !lo.SomeBy(lo.Values(r.scenarioResults), func(results []TestUnitResult) bool {
-		return !lo.SomeBy(results, func(r TestUnitResult) bool {
-			return len(r.failures) == 0
-		})
-	})
+	return r.Summary().OK()
 }
 
 // ScenarioPaths returns a sorted list of Scenario Paths.
 func (r *Run) ScenarioPaths() []string {
-	paths := lo.Keys(r.scenarioResults)
+	r.RLock()
+	defer r.RUnlock()
+	return r.scenarioPaths()
+}
+
+// scenarioPaths returns a sorted list of the paths of Scenarios having either
+// test unit results or runtime errors. The caller must hold the lock.
+func (r *Run) scenarioPaths() []string {
+	paths := lo.Union(
+		lo.Keys(r.scenarioResults),
+		lo.Keys(r.setupResults),
+		lo.Keys(r.teardownResults),
+		lo.Keys(r.scenarioErrors),
+		lo.Keys(r.scenarioSkips),
+	)
 	slices.Sort(paths)
 	return paths
 }
 
+// ScenarioErrors returns the runtime errors that occurred while executing the
+// Scenario with the supplied path.
+func (r *Run) ScenarioErrors(path string) []error {
+	r.RLock()
+	defer r.RUnlock()
+	return r.scenarioErrors[path]
+}
+
+// ScenarioSkip returns the reason the Scenario with the supplied path was
+// skipped and true, or an empty string and false if the scenario was not
+// skipped.
+func (r *Run) ScenarioSkip(path string) (string, bool) {
+	r.RLock()
+	defer r.RUnlock()
+	reason, skipped := r.scenarioSkips[path]
+	return reason, skipped
+}
+
+// StoreSkip records that the entire Scenario with the supplied path was
+// skipped, for instance because one of its `skip-if` conditions passed.
+func (r *Run) StoreSkip(
+	path string, // the Scenario.Path
+	reason string,
+) {
+	r.Lock()
+	defer r.Unlock()
+	r.scenarioSkips[path] = reason
+	r.finished = time.Now()
+}
+
+// StoreError stores a runtime error that occurred while executing the
+// Scenario with the supplied path.
+func (r *Run) StoreError(
+	path string, // the Scenario.Path
+	err error,
+) {
+	r.Lock()
+	defer r.Unlock()
+	r.scenarioErrors[path] = append(r.scenarioErrors[path], err)
+	r.finished = time.Now()
+}
+
 // ScenarioResults returns the set of TestUnitResults for a Scenario with the
 // supplied path.
 func (r *Run) ScenarioResults(path string) []TestUnitResult {
+	r.RLock()
+	defer r.RUnlock()
 	return r.scenarioResults[path]
 }
 
+// ScenarioSetupResults returns the set of TestUnitResults for the setup specs
+// of a Scenario with the supplied path.
+func (r *Run) ScenarioSetupResults(path string) []TestUnitResult {
+	r.RLock()
+	defer r.RUnlock()
+	return r.setupResults[path]
+}
+
+// ScenarioTeardownResults returns the set of TestUnitResults for the teardown
+// specs of a Scenario with the supplied path.
+func (r *Run) ScenarioTeardownResults(path string) []TestUnitResult {
+	r.RLock()
+	defer r.RUnlock()
+	return r.teardownResults[path]
+}
+
 // StoreResult stores a test unit result to the Run for the supplied test unit.
 func (r *Run) StoreResult(
 	index int,
@@ -48,17 +140,59 @@ func (r *Run) StoreResult(
 	tu *testunit.TestUnit,
 	res *api.Result,
 ) {
-	if _, ok := r.scenarioResults[path]; !ok {
-		r.scenarioResults[path] = []TestUnitResult{}
-	}
-	r.scenarioResults[path] = append(
-		r.scenarioResults[path],
+	r.Lock()
+	defer r.Unlock()
+	storeResult(r.scenarioResults, index, path, tu, res)
+	r.finished = time.Now()
+}
+
+// StoreSetupResult stores the result of one of a Scenario's setup specs to
+// the Run for the supplied test unit.
+func (r *Run) StoreSetupResult(
+	index int,
+	path string, // the Scenario.Path
+	tu *testunit.TestUnit,
+	res *api.Result,
+) {
+	r.Lock()
+	defer r.Unlock()
+	storeResult(r.setupResults, index, path, tu, res)
+	r.finished = time.Now()
+}
+
+// StoreTeardownResult stores the result of one of a Scenario's teardown specs
+// to the Run for the supplied test unit.
+func (r *Run) StoreTeardownResult(
+	index int,
+	path string, // the Scenario.Path
+	tu *testunit.TestUnit,
+	res *api.Result,
+) {
+	r.Lock()
+	defer r.Unlock()
+	storeResult(r.teardownResults, index, path, tu, res)
+	r.finished = time.Now()
+}
+
+// storeResult appends a test unit result to the supplied collection of
+// results for the Scenario with the supplied path. The caller must hold the
+// lock.
+func storeResult(
+	results map[string][]TestUnitResult,
+	index int,
+	path string,
+	tu *testunit.TestUnit,
+	res *api.Result,
+) {
+	results[path] = append(
+		results[path],
 		TestUnitResult{
 			index:    index,
 			name:     tu.Name(),
 			elapsed:  tu.Elapsed(),
 			skipped:  tu.Skipped(),
 			failures: res.Failures(),
+			hooks:    res.Hooks(),
 			detail:   tu.Detail(),
 		},
 	)
@@ -75,6 +209,9 @@ type TestUnitResult struct {
 	// failures is the collection of assertion failures for the test spec that
 	// occurred during the run. this will NOT include RuntimeErrors.
 	failures []error
+	// hooks is the collection of results of the hooks evaluated after the
+	// test spec.
+	hooks []api.HookResult
 	// elapsed is the time take to execute the test unit
 	elapsed time.Duration
 	// detail is a buffer holding any log entries made during the run of the
@@ -98,6 +235,11 @@ func (u TestUnitResult) Failures() []error {
 	return u.failures
 }
 
+// Hooks returns the results of the hooks evaluated after the test spec.
+func (u TestUnitResult) Hooks() []api.HookResult {
+	return u.hooks
+}
+
 func (u TestUnitResult) Skipped() bool {
 	return u.skipped
 }
@@ -0,0 +1,190 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package run
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Status is the outcome of a test scenario.
+type Status string
+
+const (
+	// StatusPassed indicates no test unit in the scenario failed and no
+	// runtime error occurred.
+	StatusPassed Status = "passed"
+	// StatusFailed indicates at least one test unit, setup spec or teardown
+	// spec in the scenario failed.
+	StatusFailed Status = "failed"
+	// StatusSkipped indicates the scenario itself or all test units in the
+	// scenario were skipped.
+	StatusSkipped Status = "skipped"
+	// StatusErrored indicates a runtime error occurred while executing the
+	// scenario.
+	StatusErrored Status = "errored"
+)
+
+// ScenarioSummary summarizes the outcome of a single test scenario in a Run.
+type ScenarioSummary struct {
+	// Path is the Scenario.Path.
+	Path string
+	// Status is the overall outcome of the scenario.
+	Status Status
+	// Passed is the number of the scenario's test units that passed.
+	Passed int
+	// Failed is the number of the scenario's test units that failed.
+	Failed int
+	// Skipped is the number of the scenario's test units that were skipped.
+	Skipped int
+	// Elapsed is the total time taken to execute the scenario's test units.
+	Elapsed time.Duration
+	// SetupFailed is the number of the scenario's setup specs that failed.
+	SetupFailed int
+	// TeardownFailed is the number of the scenario's teardown specs that
+	// failed.
+	TeardownFailed int
+	// SkipReason is the reason the scenario itself was skipped, if it was.
+	SkipReason string
+	// Errors contains the runtime errors that occurred while executing the
+	// scenario.
+	Errors []error
+}
+
+// Summary summarizes the outcome of a Run.
+type Summary struct {
+	// Passed is the total number of test units that passed.
+	Passed int
+	// Failed is the total number of test units that failed.
+	Failed int
+	// Skipped is the total number of test units that were skipped.
+	Skipped int
+	// Elapsed is the wall-clock time between the creation of the Run and the
+	// last result, runtime error or skip stored to it.
+	Elapsed time.Duration
+	// UnitTime is the sum of the time taken to execute each test unit. When
+	// scenarios are executed in parallel, UnitTime may exceed Elapsed.
+	UnitTime time.Duration
+	// SetupFailed is the total number of setup specs that failed.
+	SetupFailed int
+	// TeardownFailed is the total number of teardown specs that failed.
+	TeardownFailed int
+	// Scenarios contains a summary for each scenario in the Run, ordered by
+	// scenario path.
+	Scenarios []ScenarioSummary
+	// Errors contains all runtime errors that occurred during the Run,
+	// ordered by scenario path. A runtime error stored for several scenarios,
+	// e.g. a suite's fixture failing to start, is only included once.
+	Errors []error
+}
+
+// OK returns true if no test unit, setup spec or teardown spec failed and no
+// runtime errors occurred.
+func (s Summary) OK() bool {
+	return s.Failed == 0 && s.SetupFailed == 0 && s.TeardownFailed == 0 &&
+		len(s.Errors) == 0
+}
+
+// Total returns the total number of test units in the Run.
+func (s Summary) Total() int {
+	return s.Passed + s.Failed + s.Skipped
+}
+
+// String returns a single-line, human-readable description of the Summary.
+func (s Summary) String() string {
+	msg := fmt.Sprintf(
+		"%d passed, %d failed, %d skipped",
+		s.Passed, s.Failed, s.Skipped,
+	)
+	if s.SetupFailed > 0 {
+		msg += fmt.Sprintf(", %d setup failed", s.SetupFailed)
+	}
+	if s.TeardownFailed > 0 {
+		msg += fmt.Sprintf(", %d teardown failed", s.TeardownFailed)
+	}
+	if len(s.Errors) > 0 {
+		msg += fmt.Sprintf(", %d errored", len(s.Errors))
+	}
+	return msg + fmt.Sprintf(" (%s)", s.Elapsed.Round(time.Millisecond))
+}
+
+// Summary returns a Summary of the outcome of the Run.
+func (r *Run) Summary() Summary {
+	r.RLock()
+	defer r.RUnlock()
+	sum := Summary{}
+	if !r.finished.IsZero() {
+		sum.Elapsed = r.finished.Sub(r.started)
+	}
+	for _, path := range r.scenarioPaths() {
+		reason, skipped := r.scenarioSkips[path]
+		ss := ScenarioSummary{
+			Path:       path,
+			SkipReason: reason,
+			Errors:     r.scenarioErrors[path],
+		}
+		for _, res := range r.scenarioResults[path] {
+			switch {
+			case res.Skipped():
+				ss.Skipped++
+			case !res.OK():
+				ss.Failed++
+			default:
+				ss.Passed++
+			}
+			ss.Elapsed += res.Elapsed()
+		}
+		for _, res := range r.setupResults[path] {
+			if !res.OK() {
+				ss.SetupFailed++
+			}
+		}
+		for _, res := range r.teardownResults[path] {
+			if !res.OK() {
+				ss.TeardownFailed++
+			}
+		}
+		switch {
+		case len(ss.Errors) > 0:
+			ss.Status = StatusErrored
+		case ss.Failed > 0, ss.SetupFailed > 0, ss.TeardownFailed > 0:
+			ss.Status = StatusFailed
+		case skipped, ss.Skipped > 0 && ss.Passed == 0:
+			ss.Status = StatusSkipped
+		default:
+			ss.Status = StatusPassed
+		}
+		sum.Passed += ss.Passed
+		sum.Failed += ss.Failed
+		sum.Skipped += ss.Skipped
+		sum.UnitTime += ss.Elapsed
+		sum.SetupFailed += ss.SetupFailed
+		sum.TeardownFailed += ss.TeardownFailed
+		for _, err := range ss.Errors {
+			if !containsError(sum.Errors, err) {
+				sum.Errors = append(sum.Errors, err)
+			}
+		}
+		sum.Scenarios = append(sum.Scenarios, ss)
+	}
+	return sum
+}
+
+// containsError returns true if the supplied error value is in the supplied
+// collection of errors. Errors are compared by identity, not with errors.Is,
+// so that distinct errors wrapping the same sentinel are all kept.
+func containsError(errs []error, err error) bool {
+	typ := reflect.TypeOf(err)
+	if typ == nil || !typ.Comparable() {
+		return false
+	}
+	for _, e := range errs {
+		if reflect.TypeOf(e) == typ && e == err {
+			return true
+		}
+	}
+	return false
+}
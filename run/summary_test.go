@@ -0,0 +1,125 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package run_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gdt-dev/core/api"
+	"github.com/gdt-dev/core/run"
+	"github.com/gdt-dev/core/testunit"
+)
+
+func storeResult(
+	r *run.Run,
+	path string,
+	idx int,
+	failures ...error,
+) {
+	tu := testunit.New(context.TODO(), testunit.WithName(path))
+	r.StoreResult(idx, path, tu, api.NewResult(api.WithFailures(failures...)))
+}
+
+func TestOKEmpty(t *testing.T) {
+	assert := assert.New(t)
+
+	r := run.New()
+	assert.True(r.OK())
+	assert.Equal(0, r.Summary().Total())
+}
+
+func TestOKSingleFailureAmongPasses(t *testing.T) {
+	assert := assert.New(t)
+
+	r := run.New()
+	storeResult(r, "a.yaml", 0)
+	storeResult(r, "a.yaml", 1, api.ErrFailure)
+	storeResult(r, "a.yaml", 2)
+
+	assert.False(r.OK())
+}
+
+func TestOKRuntimeError(t *testing.T) {
+	assert := assert.New(t)
+
+	r := run.New()
+	storeResult(r, "a.yaml", 0)
+	r.StoreError("b.yaml", api.RuntimeError)
+
+	assert.False(r.OK())
+	assert.Equal([]string{"a.yaml", "b.yaml"}, r.ScenarioPaths())
+}
+
+func TestSummary(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	r := run.New()
+	storeResult(r, "a.yaml", 0)
+	storeResult(r, "a.yaml", 1)
+	storeResult(r, "b.yaml", 0)
+	storeResult(r, "b.yaml", 1, api.ErrFailure)
+	rterr := errors.Join(api.RuntimeError, errors.New("boom"))
+	r.StoreError("c.yaml", rterr)
+
+	sum := r.Summary()
+	assert.False(sum.OK())
+	assert.Equal(3, sum.Passed)
+	assert.Equal(1, sum.Failed)
+	assert.Equal(0, sum.Skipped)
+	assert.Equal(4, sum.Total())
+	assert.Equal([]error{rterr}, sum.Errors)
+	assert.Contains(sum.String(), "3 passed, 1 failed, 0 skipped, 1 errored")
+
+	require.Len(sum.Scenarios, 3)
+	assert.Equal("a.yaml", sum.Scenarios[0].Path)
+	assert.Equal(run.StatusPassed, sum.Scenarios[0].Status)
+	assert.Equal(2, sum.Scenarios[0].Passed)
+	assert.Equal(run.StatusFailed, sum.Scenarios[1].Status)
+	assert.Equal(1, sum.Scenarios[1].Failed)
+	assert.Equal(run.StatusErrored, sum.Scenarios[2].Status)
+	assert.Equal([]error{rterr}, sum.Scenarios[2].Errors)
+}
+
+func TestSummaryDuplicateErrors(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	r := run.New()
+	rterr := errors.Join(api.RuntimeError, errors.New("fixture failed"))
+	r.StoreError("a.yaml", rterr)
+	r.StoreError("b.yaml", rterr)
+	other := errors.Join(api.RuntimeError, errors.New("fixture failed"))
+	r.StoreError("c.yaml", other)
+
+	sum := r.Summary()
+	assert.Equal([]error{rterr, other}, sum.Errors)
+	assert.Contains(sum.String(), "2 errored")
+	require.Len(sum.Scenarios, 3)
+	for _, ss := range sum.Scenarios {
+		assert.Equal(run.StatusErrored, ss.Status)
+	}
+}
+
+func TestSummaryElapsed(t *testing.T) {
+	assert := assert.New(t)
+
+	r := run.New()
+	assert.Zero(r.Summary().Elapsed)
+
+	tu := testunit.New(context.TODO(), testunit.WithName("a.yaml"))
+	time.Sleep(10 * time.Millisecond)
+	r.StoreResult(0, "a.yaml", tu, api.NewResult())
+
+	sum := r.Summary()
+	assert.GreaterOrEqual(sum.Elapsed, 10*time.Millisecond)
+	assert.Equal(r.ScenarioResults("a.yaml")[0].Elapsed(), sum.UnitTime)
+}
@@ -6,8 +6,6 @@ package scenario
 
 import (
 	"io"
-	"os"
-	"path/filepath"
 
 	"gopkg.in/yaml.v3"
 
@@ -34,19 +32,7 @@ func FromBytes(
 	mods ...ScenarioModifier,
 ) (*Scenario, error) {
 	s := New(mods...)
-	if s.Path != "" {
-		// NOTE(jaypipes): This is necessary to allow relative path lookups for
-		// file loads *within* the test scenario itself.
-		cwd, _ := os.Getwd()
-		if err := os.Chdir(filepath.Dir(s.Path)); err != nil {
-			return nil, err
-		}
-		defer func() {
-			_ = os.Chdir(cwd)
-		}()
-	}
-	expanded := parse.ExpandWithFixedDoubleDollar(string(contents))
-	if err := yaml.Unmarshal([]byte(expanded), s); err != nil {
+	if err := s.unmarshal(contents); err != nil {
 		if ep, ok := err.(*parse.Error); ok {
 			ep.Path = s.Path
 			ep.SetContents()
@@ -57,3 +43,26 @@ func FromBytes(
 
 	return s, nil
 }
+
+// unmarshal parses the supplied contents, expands references to environment
+// variables, optionally validates the result against the Schema of the
+// registered plugins and decodes it into the Scenario.
+func (s *Scenario) unmarshal(contents []byte) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(contents, &doc); err != nil {
+		return err
+	}
+	if doc.Kind == 0 {
+		// An empty document.
+		return nil
+	}
+	if err := parse.ExpandNode(&doc, s.expandOpts...); err != nil {
+		return err
+	}
+	if s.validateSchema {
+		if err := validateSchema(&doc); err != nil {
+			return err
+		}
+	}
+	return doc.Decode(s)
+}
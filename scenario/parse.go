@@ -6,6 +6,9 @@ package scenario
 
 import (
 	"errors"
+	"maps"
+	"slices"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 
@@ -23,6 +26,7 @@ func (s *Scenario) UnmarshalYAML(node *yaml.Node) error {
 	s.Timings = &api.Timings{}
 	plugins := plugin.Registered()
 	defaults := api.Defaults{}
+	var defaultsNode *yaml.Node
 	// maps/structs are stored in a top-level Node.Content field which is a
 	// concatenated slice of Node pointers in pairs of key/values.
 	//
@@ -57,38 +61,41 @@ func (s *Scenario) UnmarshalYAML(node *yaml.Node) error {
 				return parse.ExpectedSequenceAt(valNode)
 			}
 			s.Fixtures = fixtures
+		case "on-failure":
+			var o api.OnFailure
+			if err := valNode.Decode(&o); err != nil {
+				return err
+			}
+			s.OnFailure = o
+		case "cleanup":
+			var c api.CleanupPolicy
+			if err := valNode.Decode(&c); err != nil {
+				return err
+			}
+			s.Cleanup = c
 		case "defaults":
 			if valNode.Kind != yaml.MappingNode {
 				return parse.ExpectedMapAt(valNode)
 			}
-			// Each plugin can have its own set of default configuration values
-			// under an outer map field keyed to the name of the plugin.
-			// Plugins return a Defaults prototype from
-			// `api.Plugin.Defaults()` that understands how to parse a
-			// `yaml.Node` that represents the top-level defaults object in the
-			// scenario.
-			for _, p := range plugins {
-				plugDefaults := p.Defaults()
-				if err := valNode.Decode(plugDefaults); err != nil {
-					return err
-				}
-				defaults[p.Info().Name] = plugDefaults
-			}
-			// The scenario may have its own defaults as well, so we stash
-			// these in the "scenario" pseudo-plugin key.
-			var scenDefaults Defaults
-			if err := valNode.Decode(&scenDefaults); err != nil {
+			defaultsNode = valNode
+		case "vars":
+			var vars api.Vars
+			if err := valNode.Decode(&vars); err != nil {
 				return err
 			}
-			if scenDefaults.Timeout != nil {
-				s.Timings.AddTimeout(
-					scenDefaults.Timeout.Duration(),
-					api.SetOnDefault,
-					-1,
-				)
-			}
-			defaults[DefaultsKey] = &scenDefaults
-			s.Defaults = defaults
+			s.Vars = vars
+		}
+	}
+	if len(s.inheritedDefaults) > 0 {
+		inherited := &yaml.Node{}
+		if err := inherited.Encode(s.inheritedDefaults); err != nil {
+			return err
+		}
+		defaultsNode = mergeMapNodes(inherited, defaultsNode)
+	}
+	if defaultsNode != nil {
+		if err := s.parseDefaults(defaultsNode, plugins, defaults); err != nil {
+			return err
 		}
 	}
 	for i := 0; i < len(node.Content); i += 2 {
@@ -100,92 +107,365 @@ func (s *Scenario) UnmarshalYAML(node *yaml.Node) error {
 		valNode := node.Content[i+1]
 		switch key {
 		case "tests":
-			if valNode.Kind != yaml.SequenceNode {
-				return parse.ExpectedSequenceAt(valNode)
+			specs, err := s.parseSpecs(
+				phaseTest, valNode, plugins, &defaults,
+			)
+			if err != nil {
+				return err
 			}
-			for idx, testNode := range valNode.Content {
-				parsed := false
-				base := api.Spec{}
-				if err := testNode.Decode(&base); err != nil {
-					return err
-				}
-				base.Index = idx
-				base.Defaults = &defaults
-				pluginSpecs := map[api.Plugin][]api.Evaluable{}
-				for _, p := range plugins {
-					pluginSpecs[p] = p.Specs()
-				}
-				for plugin, specs := range pluginSpecs {
-					for idx, sp := range specs {
-						if err := testNode.Decode(sp); err != nil {
-							if errors.Is(err, parse.ErrParseUnknownField) {
-								continue
-							}
-							return err
-						}
-						base.Plugin = plugin
-						if base.Wait != nil {
-							if base.Wait.Before != "" {
-								s.Timings.AddWait(
-									base.Wait.BeforeDuration(),
-								)
-							}
-							if base.Wait.After != "" {
-								s.Timings.AddWait(
-									base.Wait.AfterDuration(),
-								)
-							}
-						}
-						if base.Timeout != nil {
-							s.Timings.AddTimeout(
-								base.Timeout.Duration(),
-								api.SetOnSpec,
-								idx,
-							)
-						}
-						sp.SetBase(base)
-						s.Tests = append(s.Tests, sp)
-						parsed = true
-						break
-					}
-				}
-				if !parsed {
-					return parse.UnknownSpecAt(s.Path, valNode)
-				}
+			s.Tests = specs
+		case "setup":
+			specs, err := s.parseSpecs(
+				phaseSetup, valNode, plugins, &defaults,
+			)
+			if err != nil {
+				return err
+			}
+			s.Setup = specs
+		case "teardown":
+			specs, err := s.parseSpecs(
+				phaseTeardown, valNode, plugins, &defaults,
+			)
+			if err != nil {
+				return err
 			}
+			s.Teardown = specs
 		case "skip-if":
 			if valNode.Kind != yaml.SequenceNode {
 				return parse.ExpectedSequenceAt(valNode)
 			}
 			for idx, testNode := range valNode.Content {
-				parsed := false
 				base := api.Spec{}
 				if err := testNode.Decode(&base); err != nil {
 					return err
 				}
 				base.Index = idx
 				base.Defaults = &defaults
-				specs := []api.Evaluable{}
-				for _, p := range plugins {
-					specs = append(specs, p.Specs()...)
+				parsed, err := s.parseSpec(testNode, plugins, &base)
+				if err != nil {
+					return err
 				}
-				for _, sp := range specs {
-					if err := testNode.Decode(sp); err != nil {
-						if errors.Is(err, parse.ErrParseUnknownField) {
-							continue
-						}
-						return err
-					}
-					sp.SetBase(base)
-					s.SkipIf = append(s.SkipIf, sp)
-					parsed = true
-					break
+				if err := s.setBase(parsed, base); err != nil {
+					return err
 				}
-				if !parsed {
-					return parse.UnknownSpecAt(s.Path, valNode)
+				s.SkipIf = append(s.SkipIf, parsed)
+			}
+		}
+	}
+	return nil
+}
+
+// parseSpecs asks plugins to parse each of the test spec definitions in the
+// supplied sequence node and returns the parsed plugin Spec structs. Waits
+// found in the test specs are added to the scenario's Timings. Timeouts are
+// only added for the test specs in the test phase, since the Timings' spec
+// index refers to the scenario's tests.
+func (s *Scenario) parseSpecs(
+	phase string,
+	node *yaml.Node,
+	plugins []api.Plugin,
+	defaults *api.Defaults,
+) ([]api.Evaluable, error) {
+	if node.Kind != yaml.SequenceNode {
+		return nil, parse.ExpectedSequenceAt(node)
+	}
+	parsedSpecs := []api.Evaluable{}
+	for idx, specNode := range node.Content {
+		base := api.Spec{}
+		if err := specNode.Decode(&base); err != nil {
+			return nil, err
+		}
+		base.Index = idx
+		base.Defaults = defaults
+		parsed, err := s.parseSpec(specNode, plugins, &base)
+		if err != nil {
+			return nil, err
+		}
+		if onNode := mapValue(specNode, "on"); onNode != nil {
+			hooks, err := s.parseHooks(onNode, plugins, defaults)
+			if err != nil {
+				return nil, err
+			}
+			base.On = hooks
+		}
+		if base.Wait != nil {
+			if base.Wait.Before != "" {
+				s.Timings.AddWait(base.Wait.BeforeDuration())
+			}
+			if base.Wait.After != "" {
+				s.Timings.AddWait(base.Wait.AfterDuration())
+			}
+		}
+		if err := s.setBase(parsed, base); err != nil {
+			return nil, err
+		}
+		if phase == phaseTest {
+			if base.Timeout != nil {
+				s.Timings.AddTimeout(
+					base.Timeout.Duration(),
+					api.SetOnSpec,
+					idx,
+				)
+			}
+			if to := parsed.Timeout(); to != nil {
+				s.Timings.AddTimeout(to.Duration(), api.SetOnPlugin, idx)
+			}
+		}
+		parsedSpecs = append(parsedSpecs, parsed)
+	}
+	return parsedSpecs, nil
+}
+
+// setBase sets the supplied parsed test spec's base Spec and, if the test spec
+// refers to files by relative path, resolves those paths against the
+// scenario's base directory.
+func (s *Scenario) setBase(parsed api.Evaluable, base api.Spec) error {
+	parsed.SetBase(base)
+	if pr, ok := parsed.(api.PathResolver); ok {
+		return pr.ResolvePaths(s.BaseDir)
+	}
+	return nil
+}
+
+// parseSpec asks plugins to parse the supplied test spec definition and
+// returns the parsed plugin Spec struct, setting the supplied base Spec's
+// Plugin to the plugin that parsed it. If no plugin could parse it, the
+// returned error lists each plugin's reason.
+//
+// Plugins are asked in the supplied order. If the base Spec names a plugin,
+// only that plugin is asked. Otherwise, exactly one plugin must be able to
+// parse the test spec definition.
+func (s *Scenario) parseSpec(
+	specNode *yaml.Node,
+	plugins []api.Plugin,
+	base *api.Spec,
+) (api.Evaluable, error) {
+	if base.PluginName != "" {
+		idx := slices.IndexFunc(plugins, func(p api.Plugin) bool {
+			return strings.EqualFold(p.Info().Name, base.PluginName)
+		})
+		if idx < 0 {
+			return nil, parse.UnknownPluginAt(
+				s.Path, base.PluginName, mapValue(specNode, "plugin"),
+			)
+		}
+		plugins = plugins[idx : idx+1]
+	}
+	var parsed api.Evaluable
+	matched := []string{}
+	rejections := []parse.SpecRejection{}
+	for _, p := range plugins {
+		sp, err := parsePluginSpec(specNode, p)
+		if errors.Is(err, parse.ErrParseUnknownField) {
+			rejections = append(rejections, parse.SpecRejection{
+				Plugin: p.Info().Name,
+				Err:    err,
+				Fields: specFields(p),
+			})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if sp == nil {
+			continue
+		}
+		if parsed == nil {
+			parsed = sp
+			base.Plugin = p
+		}
+		matched = append(matched, p.Info().Name)
+	}
+	if len(matched) > 1 {
+		return nil, parse.AmbiguousSpecAt(s.Path, specNode, matched...)
+	}
+	if parsed == nil {
+		return nil, parse.UnknownSpecAt(s.Path, specNode, rejections...)
+	}
+	return parsed, nil
+}
+
+// parsePluginSpec returns the first of the supplied plugin's Spec types that
+// the supplied test spec definition could be parsed into. If the plugin does
+// not know a field in the test spec definition, the returned error wraps
+// parse.ErrParseUnknownField. Returns nil and no error if the plugin has no
+// Spec types.
+func parsePluginSpec(
+	specNode *yaml.Node,
+	p api.Plugin,
+) (api.Evaluable, error) {
+	var unknown error
+	for _, sp := range p.Specs() {
+		if err := specNode.Decode(sp); err != nil {
+			if errors.Is(err, parse.ErrParseUnknownField) {
+				if unknown == nil {
+					unknown = err
 				}
+				continue
 			}
+			return nil, err
 		}
+		return sp, nil
 	}
+	return nil, unknown
+}
+
+// specFields returns the names of the fields accepted by the supplied
+// plugin's test specs, which are the base test spec fields along with any
+// fields described by the plugin's SpecSchema.
+func specFields(p api.Plugin) []string {
+	fields := slices.Clone(api.BaseSpecFields)
+	if sp, ok := p.(api.SchemaProvider); ok {
+		if props, ok := sp.SpecSchema()["properties"].(api.Schema); ok {
+			fields = append(fields, slices.Sorted(maps.Keys(props))...)
+		}
+	}
+	return fields
+}
+
+// parseHooks asks plugins to parse the test spec definitions in the supplied
+// `on` mapping node and returns the parsed Hooks. Each of the `fail`,
+// `success` and `always` fields may contain a single test spec definition or
+// a sequence of them.
+func (s *Scenario) parseHooks(
+	node *yaml.Node,
+	plugins []api.Plugin,
+	defaults *api.Defaults,
+) (*api.Hooks, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, parse.ExpectedMapAt(node)
+	}
+	hooks := &api.Hooks{}
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return nil, parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		var target *[]api.Evaluable
+		switch api.HookEvent(key) {
+		case api.HookFail:
+			target = &hooks.Fail
+		case api.HookSuccess:
+			target = &hooks.Success
+		case api.HookAlways:
+			target = &hooks.Always
+		default:
+			return nil, parse.UnknownFieldAt(key, keyNode)
+		}
+		hookNodes := []*yaml.Node{valNode}
+		switch valNode.Kind {
+		case yaml.MappingNode:
+		case yaml.SequenceNode:
+			hookNodes = valNode.Content
+		default:
+			return nil, parse.ExpectedMapAt(valNode)
+		}
+		for idx, hookNode := range hookNodes {
+			base := api.Spec{}
+			if err := hookNode.Decode(&base); err != nil {
+				return nil, err
+			}
+			base.Index = idx
+			base.Defaults = defaults
+			parsed, err := s.parseSpec(hookNode, plugins, &base)
+			if err != nil {
+				return nil, err
+			}
+			if err := s.setBase(parsed, base); err != nil {
+				return nil, err
+			}
+			*target = append(*target, parsed)
+		}
+	}
+	return hooks, nil
+}
+
+// mapValue returns the value node for the supplied key in the supplied
+// mapping node, or nil if the key is not present.
+func mapValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// parseDefaults asks each plugin to interpret the supplied `defaults` mapping
+// node into known configuration values for that plugin and stores the
+// results, along with the scenario's own defaults, in the supplied Defaults.
+func (s *Scenario) parseDefaults(
+	node *yaml.Node,
+	plugins []api.Plugin,
+	defaults api.Defaults,
+) error {
+	// Each plugin can have its own set of default configuration values under
+	// an outer map field keyed to the name of the plugin. Plugins return a
+	// Defaults prototype from `api.Plugin.Defaults()` that understands how to
+	// parse a `yaml.Node` that represents the top-level defaults object in
+	// the scenario.
+	for _, p := range plugins {
+		plugDefaults := p.Defaults()
+		if err := node.Decode(plugDefaults); err != nil {
+			return err
+		}
+		defaults[p.Info().Name] = plugDefaults
+	}
+	// The scenario may have its own defaults as well, so we stash these in
+	// the "scenario" pseudo-plugin key.
+	var scenDefaults Defaults
+	if err := node.Decode(&scenDefaults); err != nil {
+		return err
+	}
+	if scenDefaults.Timeout != nil {
+		s.Timings.AddTimeout(
+			scenDefaults.Timeout.Duration(),
+			api.SetOnDefault,
+			-1,
+		)
+	}
+	defaults[DefaultsKey] = &scenDefaults
+	s.Defaults = defaults
 	return nil
 }
+
+// mergeMapNodes returns a new mapping node containing the keys and values of
+// the base mapping node overlaid with the keys and values of the override
+// mapping node. Nested mapping nodes are merged recursively and, for any other
+// kind of value, the override's value wins. Neither supplied node is modified.
+func mergeMapNodes(base *yaml.Node, override *yaml.Node) *yaml.Node {
+	if base != nil && base.Kind == yaml.DocumentNode && len(base.Content) > 0 {
+		base = base.Content[0]
+	}
+	if override == nil {
+		return base
+	}
+	if base == nil || base.Kind != yaml.MappingNode ||
+		override.Kind != yaml.MappingNode {
+		return override
+	}
+	merged := *override
+	merged.Content = slices.Clone(base.Content)
+	for i := 0; i < len(override.Content); i += 2 {
+		keyNode := override.Content[i]
+		valNode := override.Content[i+1]
+		found := false
+		for j := 0; j < len(merged.Content); j += 2 {
+			if merged.Content[j].Value != keyNode.Value {
+				continue
+			}
+			merged.Content[j+1] = mergeMapNodes(merged.Content[j+1], valNode)
+			found = true
+			break
+		}
+		if !found {
+			merged.Content = append(merged.Content, keyNode, valNode)
+		}
+	}
+	return &merged
+}
@@ -5,9 +5,11 @@
 package scenario_test
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/gdt-dev/core/api"
 	"github.com/gdt-dev/core/parse"
@@ -16,6 +18,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/gdt-dev/core/internal/testutil/plugin/bar"
+	"github.com/gdt-dev/core/internal/testutil/plugin/cleaner"
 	"github.com/gdt-dev/core/internal/testutil/plugin/failer"
 	"github.com/gdt-dev/core/internal/testutil/plugin/foo"
 	"github.com/gdt-dev/core/internal/testutil/plugin/priorrun"
@@ -65,6 +68,7 @@ func TestNoTests(t *testing.T) {
 				InnerDefaults: failer.InnerDefaults{},
 			},
 			"priorRun":           &priorrun.Defaults{},
+			"cleaner":            &cleaner.Defaults{},
 			scenario.DefaultsKey: &scenario.Defaults{},
 		},
 		s.Defaults,
@@ -95,8 +99,84 @@ func TestUnknownSpec(t *testing.T) {
 	require.Nil(err)
 
 	s, err := scenario.FromReader(f, scenario.WithPath(fp))
-	assert.NotNil(err)
+	require.NotNil(err)
 	assert.Nil(s)
+
+	var perr *parse.Error
+	require.ErrorAs(err, &perr)
+	assert.Equal(4, perr.Line)
+	assert.Equal(5, perr.Column)
+	assert.Contains(perr.Message, "no plugin could parse spec definition")
+	for _, name := range []string{"bar", "cleaner", "fail", "foo", "priorRun"} {
+		assert.Contains(perr.Message, "\n  "+name+": unknown field")
+	}
+	assert.Contains(perr.Message, `"gibber" at line 4, column 5`)
+}
+
+func TestAmbiguousSpec(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "ambiguous-spec.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.Nil(s)
+
+	var perr *parse.Error
+	require.ErrorAs(err, &perr)
+	assert.Equal(5, perr.Line)
+	assert.Contains(perr.Message, "ambiguous spec definition")
+	assert.Contains(perr.Message, "bar, fail, foo, priorRun")
+}
+
+func TestUnknownPlugin(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "unknown-plugin.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.Nil(s)
+
+	var perr *parse.Error
+	require.ErrorAs(err, &perr)
+	assert.Equal(4, perr.Line)
+	assert.Equal(`unknown plugin "baz"`, perr.Message)
+}
+
+func TestPluginField(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "plugin-field.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.Nil(err)
+	require.NotNil(s)
+	require.Len(s.Tests, 2)
+
+	assert.IsType(&bar.Spec{}, s.Tests[0])
+	assert.Equal(bar.PluginRef, s.Tests[0].Base().Plugin)
+	assert.Equal("bar", s.Tests[0].Base().PluginName)
+	assert.IsType(&foo.Spec{}, s.Tests[1])
+	assert.Equal(foo.PluginRef, s.Tests[1].Base().Plugin)
+
+	// Each spec that more than one plugin could parse is only added once, in
+	// every phase of the scenario.
+	require.Len(s.SkipIf, 1)
+	assert.IsType(&bar.Spec{}, s.SkipIf[0])
+	require.Len(s.Setup, 1)
+	assert.IsType(&foo.Spec{}, s.Setup[0])
+	require.Len(s.Teardown, 1)
+	assert.IsType(&priorrun.Spec{}, s.Teardown[0])
 }
 
 func TestTimeoutScalarOrMap(t *testing.T) {
@@ -111,6 +191,26 @@ func TestTimeoutScalarOrMap(t *testing.T) {
 	assert.Nil(err)
 }
 
+func TestSetupTeardownTimings(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "setup-teardown-timeout.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.Nil(err)
+	require.NotNil(s)
+
+	// Only the test specs' timeouts are considered, since the max timeout's
+	// spec index refers to the scenario's tests.
+	assert.Equal(2*time.Second, s.Timings.MaxTimeout)
+	assert.Equal(api.SetOnSpec, s.Timings.MaxTimeoutSetOn)
+	assert.Equal(1, s.Timings.MaxTimeoutSpecIndex)
+	assert.Equal(time.Second, s.Timings.TotalWait)
+}
+
 func TestBadTimeout(t *testing.T) {
 	assert := assert.New(t)
 	require := require.New(t)
@@ -189,6 +289,168 @@ func TestBadRetryIntervalDuration(t *testing.T) {
 	assert.Nil(s)
 }
 
+func TestBadOnFailure(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	for _, fname := range []string{
+		"bad-on-failure.yaml",
+		"bad-on-failure-spec.yaml",
+	} {
+		fp := filepath.Join("testdata", "parse", "fail", fname)
+		f, err := os.Open(fp)
+		require.Nil(err)
+
+		s, err := scenario.FromReader(f, scenario.WithPath(fp))
+		assert.ErrorContains(err, `expected one of stop, continue but got "sometimes"`)
+		assert.Nil(s)
+	}
+}
+
+func TestBadCleanup(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-cleanup.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	assert.ErrorContains(
+		err,
+		`expected one of always, on-success, on-failure but got "sometimes"`,
+	)
+	assert.Nil(s)
+}
+
+func TestBadHook(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-hook.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	assert.ErrorIs(err, parse.ErrParseUnknownField)
+	assert.ErrorContains(err, "sometimes")
+	assert.Nil(s)
+}
+
+func TestVars(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "vars.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.Nil(err)
+	require.NotNil(s)
+	require.Len(s.Vars, 5)
+
+	names := []string{}
+	for _, v := range s.Vars {
+		names = append(names, v.Name)
+	}
+	assert.Equal([]string{"REPLICAS", "NAMES", "HOST", "TOKEN", "LABELS"}, names)
+	assert.Equal(3, s.Vars[0].Value)
+	assert.Equal([]any{"a", "b"}, s.Vars[1].Value)
+	assert.Equal("GDT_TEST_VARS_HOST", s.Vars[2].Env)
+	assert.Equal("localhost", s.Vars[2].Default)
+	assert.Equal("token.txt", s.Vars[3].File)
+	assert.Equal(map[string]any{"app": "nginx"}, s.Vars[4].Value)
+}
+
+func TestBadVar(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-var.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	assert.ErrorContains(err, "expected exactly one of value, env or file")
+	assert.Nil(s)
+}
+
+func TestStrictEnvExpansion(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "undefined-env.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+		scenario.WithEnvExpansion(parse.WithStrictEnv(true)),
+	)
+	require.NotNil(err)
+	assert.Nil(s)
+
+	var perr *parse.Error
+	require.ErrorAs(err, &perr)
+	assert.Equal(fp, perr.Path)
+	assert.Equal(4, perr.Line)
+	assert.ErrorContains(err, "GDT_TEST_UNDEFINED_ENV")
+}
+
+func TestSchema(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	sch := scenario.Schema()
+	defs, ok := sch["definitions"].(api.Schema)
+	require.True(ok)
+	for _, name := range []string{"foo", "bar", "fail", "priorRun", "cleaner"} {
+		assert.Contains(defs, "spec."+name)
+	}
+	assert.Contains(defs, "spec")
+
+	b, err := scenario.SchemaJSON()
+	require.Nil(err)
+	assert.True(json.Valid(b))
+}
+
+func TestSchemaValidation(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "vars.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+		scenario.WithSchemaValidation(true),
+	)
+	require.Nil(err)
+	require.NotNil(s)
+
+	contents := []byte(`name: bad-var-field
+vars:
+  HOST:
+    envv: HOST
+tests:
+  - foo: bar
+`)
+	s, err = scenario.FromBytes(
+		contents, scenario.WithSchemaValidation(true),
+	)
+	require.NotNil(err)
+	assert.Nil(s)
+
+	var perr *parse.Error
+	require.ErrorAs(err, &perr)
+	assert.Equal(4, perr.Line)
+	assert.Contains(perr.Message, "envv")
+}
+
 func TestKnownSpec(t *testing.T) {
 	assert := assert.New(t)
 	require := require.New(t)
@@ -217,6 +479,7 @@ func TestKnownSpec(t *testing.T) {
 				InnerDefaults: failer.InnerDefaults{},
 			},
 			"priorRun":           &priorrun.Defaults{},
+			"cleaner":            &cleaner.Defaults{},
 			scenario.DefaultsKey: &scenario.Defaults{},
 		},
 		s.Defaults,
@@ -232,6 +495,7 @@ func TestKnownSpec(t *testing.T) {
 			InnerDefaults: failer.InnerDefaults{},
 		},
 		"priorRun":           &priorrun.Defaults{},
+		"cleaner":            &cleaner.Defaults{},
 		scenario.DefaultsKey: &scenario.Defaults{},
 	}
 	expTests := []api.Evaluable{
@@ -325,6 +589,7 @@ func TestEnvExpansion(t *testing.T) {
 				InnerDefaults: failer.InnerDefaults{},
 			},
 			"priorRun":           &priorrun.Defaults{},
+			"cleaner":            &cleaner.Defaults{},
 			scenario.DefaultsKey: &scenario.Defaults{},
 		},
 		s.Defaults,
@@ -340,6 +605,7 @@ func TestEnvExpansion(t *testing.T) {
 			InnerDefaults: failer.InnerDefaults{},
 		},
 		"priorRun":           &priorrun.Defaults{},
+		"cleaner":            &cleaner.Defaults{},
 		scenario.DefaultsKey: &scenario.Defaults{},
 	}
 	expTests := []api.Evaluable{
@@ -389,6 +655,7 @@ func TestScenarioDefaults(t *testing.T) {
 				InnerDefaults: failer.InnerDefaults{},
 			},
 			"priorRun": &priorrun.Defaults{},
+			"cleaner":  &cleaner.Defaults{},
 			scenario.DefaultsKey: &scenario.Defaults{
 				Timeout: &api.Timeout{
 					After: "2s",
@@ -404,6 +671,7 @@ func TestScenarioDefaults(t *testing.T) {
 			InnerDefaults: failer.InnerDefaults{},
 		},
 		"priorRun": &priorrun.Defaults{},
+		"cleaner":  &cleaner.Defaults{},
 		scenario.DefaultsKey: &scenario.Defaults{
 			Timeout: &api.Timeout{
 				After: "2s",
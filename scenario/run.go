@@ -7,8 +7,6 @@ package scenario
 import (
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
 	"slices"
 	"strconv"
 	"strings"
@@ -24,6 +22,18 @@ import (
 	"github.com/gdt-dev/core/testunit"
 )
 
+const (
+	// phaseSetup is the name of the phase of a scenario in which its setup
+	// specs are executed.
+	phaseSetup = "setup"
+	// phaseTest is the name of the phase of a scenario in which its tests are
+	// executed.
+	phaseTest = ""
+	// phaseTeardown is the name of the phase of a scenario in which its
+	// teardown specs are executed.
+	phaseTeardown = "teardown"
+)
+
 // Run executes the scenario. The error that is returned will always be derived
 // from `api.RuntimeError` and represents an *unrecoverable* error.
 //
@@ -32,20 +42,13 @@ import (
 // will mark the test units failed or skipped if a test unit evaluates to
 // false.
 func (s *Scenario) Run(ctx context.Context, subject any) error {
-	if s.Path != "" {
-		// NOTE(jaypipes): This is necessary to allow relative path lookups for
-		// file loads *within* the test scenario itself.
-		cwd, _ := os.Getwd()
-		if err := os.Chdir(filepath.Dir(s.Path)); err != nil {
-			return err
-		}
-		defer func() {
-			_ = os.Chdir(cwd)
-		}()
+	ctx, err := s.runContext(ctx, subject)
+	if err != nil {
+		return err
 	}
 	switch subject := subject.(type) {
 	case *testing.T:
-		return s.runGo(ctx, subject)
+		return s.runGo(ctx, subject, false)
 	case *run.Run:
 		return s.runExternal(ctx, subject)
 	default:
@@ -53,6 +56,47 @@ func (s *Scenario) Run(ctx context.Context, subject any) error {
 	}
 }
 
+// RunInSubtest executes the scenario using the `go test` tool as the
+// underlying test runner, like Run, except that the scenario's test specs are
+// executed directly in the supplied `*testing.T` instead of in a new subtest
+// named for the scenario. The caller is expected to have created the supplied
+// `*testing.T` for the scenario, e.g. with `t.Run(s.Title(), ...)`, which
+// allows a test suite to run its scenarios in parallel while keeping the same
+// test names as when they are run serially.
+func (s *Scenario) RunInSubtest(ctx context.Context, t *testing.T) error {
+	ctx, err := s.runContext(ctx, t)
+	if err != nil {
+		return err
+	}
+	return s.runGo(ctx, t, true)
+}
+
+// runContext returns the supplied context with the scenario's base directory
+// and resolved variables set.
+func (s *Scenario) runContext(
+	ctx context.Context,
+	subject any,
+) (context.Context, error) {
+	if s.BaseDir != "" {
+		// Relative path lookups for file loads *within* the test scenario
+		// itself are resolved against the scenario's base directory. We carry
+		// that directory in the context instead of changing the process'
+		// working directory so that scenarios can safely be run in parallel.
+		ctx = gdtcontext.SetBaseDir(ctx, s.BaseDir)
+	}
+	if len(s.Vars) > 0 {
+		vars, err := s.Vars.Resolve(s.BaseDir)
+		if err != nil {
+			if r, ok := subject.(*run.Run); ok {
+				r.StoreError(s.Path, err)
+			}
+			return ctx, err
+		}
+		ctx = gdtcontext.SetRun(ctx, vars)
+	}
+	return ctx, nil
+}
+
 // runExternal executes the scenario using the `gdt` CLI tool as the underlying
 // test runner and a `*RunState` to track test run state. The error that is
 // returned will always be derived from `api.RuntimeError` and represents an
@@ -72,12 +116,20 @@ func (s *Scenario) runExternal(ctx context.Context, run *run.Run) error {
 	if len(s.Fixtures) > 0 {
 		fixtures := gdtcontext.Fixtures(ctx)
 		for _, fname := range s.Fixtures {
+			if gdtcontext.FixtureStarted(ctx, fname) {
+				// Started (and later stopped) by the enclosing test suite.
+				continue
+			}
 			lookup := strings.ToLower(fname)
 			fix, found := fixtures[lookup]
 			if !found {
-				return api.RequiredFixtureMissing(fname)
+				err := api.RequiredFixtureMissing(fname)
+				run.StoreError(s.Path, err)
+				return err
 			}
 			if err := fix.Start(ctx); err != nil {
+				err = api.FixtureStartFailed(fname, err)
+				run.StoreError(s.Path, err)
 				return err
 			}
 			defer fix.Stop(ctx)
@@ -90,67 +142,198 @@ func (s *Scenario) runExternal(ctx context.Context, run *run.Run) error {
 	for _, skipIf := range s.SkipIf {
 		res, err := skipIf.Eval(ctx)
 		if err != nil {
+			run.StoreError(s.Path, err)
 			return err
 		}
 		if len(res.Failures()) == 0 {
-			rootUnit.Skipf(
+			reason := fmt.Sprintf(
 				"skip-if: %s passed. skipping test.",
 				skipIf.Base().Title(),
 			)
+			rootUnit.Skip(reason)
+			run.StoreSkip(s.Path, reason)
 			return nil
 		}
 	}
 
 	var err error
 
-	scenCleanups := []func(){}
+	scenCleanups := []func() error{}
 	scenOK := true
-	for idx, t := range s.Tests {
-		tu := testunit.New(
-			ctx,
-			testunit.WithName(
-				fmt.Sprintf(
-					"%s/%s",
-					s.Title(),
-					t.Base().Title(),
-				),
-			),
-		)
-		ctx = gdtcontext.SetTestUnit(ctx, tu)
-		res, err := s.runSpec(ctx, tu, idx)
+
+	// Setup specs are executed before any test and if any of them fails or
+	// returns a runtime error, we do not run the scenario's tests.
+	setupOK := true
+	for idx, spec := range s.Setup {
+		tu := s.newTestUnit(ctx, phaseSetup, spec)
+		var res *api.Result
+		ctx, res, err = s.runExternalSpec(ctx, tu, phaseSetup, idx, spec)
 		if err != nil {
+			run.StoreError(s.Path, err)
+			setupOK = false
 			break
 		}
+		scenCleanups = append(scenCleanups, resultCleanups(res)...)
+		run.StoreSetupResult(idx, s.Path, tu, res)
+		if tu.Failed() {
+			setupOK = false
+			break
+		}
+	}
+	scenOK = setupOK
 
-		scenCleanups = append(scenCleanups, res.Cleanups()...)
-
-		// Results can have arbitrary run data stored in them and we
-		// save this prior run data in the top-level context (and pass
-		// that context to the next Run invocation).
-		if res.HasData() {
-			ctx = gdtcontext.SetRun(ctx, res.Data())
+	stopped := false
+	for idx, t := range s.Tests {
+		if err != nil {
+			break
 		}
-		if len(res.Failures()) > 0 {
-			tu.FailNow()
+		tu := s.newTestUnit(ctx, phaseTest, t)
+		if !setupOK {
+			tu.Skip("setup failed. skipping test.")
+			run.StoreResult(idx, s.Path, tu, api.NewResult())
+			continue
+		}
+		if stopped {
+			// A prior test spec failed and the on-failure policy says to
+			// stop, so we record the remaining test specs as skipped.
+			tu.Skip("on-failure: stop. skipping test after prior failure.")
+			run.StoreResult(idx, s.Path, tu, api.NewResult())
+			continue
+		}
+		var res *api.Result
+		ctx, res, err = s.runExternalSpec(ctx, tu, phaseTest, idx, t)
+		if err != nil {
+			run.StoreError(s.Path, err)
+			break
 		}
+
+		scenCleanups = append(scenCleanups, resultCleanups(res)...)
 		scenOK = scenOK && !tu.Failed()
 
 		run.StoreResult(idx, s.Path, tu, res)
+		if tu.Failed() && s.onFailure(ctx, t) == api.OnFailureStop {
+			stopped = true
+		}
 	}
+
+	// Teardown specs are always executed, regardless of whether any setup
+	// spec or test failed or returned a runtime error.
+	for idx, spec := range s.Teardown {
+		tu := s.newTestUnit(ctx, phaseTeardown, spec)
+		var res *api.Result
+		var tdErr error
+		ctx, res, tdErr = s.runExternalSpec(ctx, tu, phaseTeardown, idx, spec)
+		if tdErr != nil {
+			run.StoreError(s.Path, tdErr)
+			if err == nil {
+				err = tdErr
+			}
+			continue
+		}
+		scenCleanups = append(scenCleanups, resultCleanups(res)...)
+		scenOK = scenOK && !tu.Failed()
+		run.StoreTeardownResult(idx, s.Path, tu, res)
+	}
+
+	// Cleanups are executed after teardown, in reverse order of
+	// registration, according to the scenario's cleanup policy. A runtime
+	// error counts as a scenario failure for the purpose of the policy.
 	slices.Reverse(scenCleanups)
-	if scenOK {
+	if s.Cleanup.Applies(!scenOK || err != nil) {
 		for _, cleanup := range scenCleanups {
-			cleanup()
+			if cErr := runCleanup(cleanup); cErr != nil {
+				run.StoreError(s.Path, cErr)
+			}
 		}
 	}
 	return err
 }
 
+// resultCleanups returns the supplied Result's cleanup functions, both those
+// that return an error and those that don't, in first-in, first-out order.
+// Within a Result, cleanup functions that don't return an error come first.
+func resultCleanups(res *api.Result) []func() error {
+	cleanups := []func() error{}
+	for _, fn := range res.Cleanups() {
+		cleanups = append(cleanups, func() error {
+			fn()
+			return nil
+		})
+	}
+	return append(cleanups, res.CleanupsWithError()...)
+}
+
+// runCleanup executes the supplied cleanup function, returning an
+// `api.ErrCleanup` if the cleanup function returns an error or panics.
+func runCleanup(cleanup func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = api.CleanupPanicked(r)
+		}
+	}()
+	if cErr := cleanup(); cErr != nil {
+		return api.CleanupFailed(cErr)
+	}
+	return nil
+}
+
+// newTestUnit returns a new test unit for the supplied test spec executed
+// during the supplied phase of the scenario.
+func (s *Scenario) newTestUnit(
+	ctx context.Context,
+	phase string,
+	spec api.Evaluable,
+) *testunit.TestUnit {
+	name := s.Title() + "/"
+	if phase != phaseTest {
+		name += phase + "/"
+	}
+	return testunit.New(
+		ctx,
+		testunit.WithName(name+spec.Base().Title()),
+	)
+}
+
+// runExternalSpec executes a single test spec using the supplied test unit to
+// track test run state, marking the test unit failed if the test spec failed.
+// The returned context contains any run data stored in the test spec's
+// result.
+func (s *Scenario) runExternalSpec(
+	ctx context.Context,
+	tu *testunit.TestUnit,
+	phase string,
+	idx int,
+	spec api.Evaluable,
+) (context.Context, *api.Result, error) {
+	ctx = gdtcontext.SetTestUnit(ctx, tu)
+	res, err := s.runSpec(ctx, phase, idx, spec)
+	if err != nil {
+		return ctx, nil, err
+	}
+	// Results can have arbitrary run data stored in them and we save this
+	// prior run data in the top-level context (and pass that context to the
+	// next Run invocation).
+	if res.HasData() {
+		ctx = gdtcontext.SetRun(ctx, res.Data())
+	}
+	logHookResults(tu, res)
+	if res.Failed() {
+		tu.FailNow()
+	}
+	return ctx, res, nil
+}
+
 // runGo executes the scenario using the `go test` tool as the underlying test
-// runner and the Go `*testing.T` to track test run state. The error that is
-// returned will always be derived from `api.RuntimeError` and represents an
-// *unrecoverable* error.
-func (s *Scenario) runGo(ctx context.Context, t *testing.T) error {
+// runner and the Go `*testing.T` to track test run state. Unless inSubtest is
+// true, in which case the supplied `*testing.T` already belongs to the
+// scenario, the scenario's test specs are executed in a subtest named for the
+// scenario. The error that is returned will always be derived from
+// `api.RuntimeError` and represents an *unrecoverable* error.
+func (s *Scenario) runGo(
+	ctx context.Context,
+	t *testing.T,
+	inSubtest bool,
+) error {
 	ctx = gdtcontext.PushTrace(ctx, s.Title())
 	defer func() {
 		ctx = gdtcontext.PopTrace(ctx)
@@ -163,13 +346,17 @@ func (s *Scenario) runGo(ctx context.Context, t *testing.T) error {
 	if len(s.Fixtures) > 0 {
 		fixtures := gdtcontext.Fixtures(ctx)
 		for _, fname := range s.Fixtures {
+			if gdtcontext.FixtureStarted(ctx, fname) {
+				// Started (and later stopped) by the enclosing test suite.
+				continue
+			}
 			lookup := strings.ToLower(fname)
 			fix, found := fixtures[lookup]
 			if !found {
 				return api.RequiredFixtureMissing(fname)
 			}
 			if err := fix.Start(ctx); err != nil {
-				return err
+				return api.FixtureStartFailed(fname, err)
 			}
 			defer fix.Stop(ctx)
 		}
@@ -192,33 +379,140 @@ func (s *Scenario) runGo(ctx context.Context, t *testing.T) error {
 		}
 	}
 
-	var res *api.Result
 	var err error
 
-	t.Run(s.Title(), func(tt *testing.T) {
-		for idx := range s.Tests {
-			res, err = s.runSpec(ctx, tt, idx)
-			if err != nil {
-				break
+	runScenario := func(tt *testing.T) {
+		// Setup specs are executed before any test and if any of them fails
+		// or returns a runtime error, we do not run the scenario's tests.
+		setupOK := true
+		if len(s.Setup) > 0 {
+			setupOK = tt.Run(phaseSetup, func(st *testing.T) {
+				ctx, err = s.runGoSpecs(
+					ctx, tt, st, phaseSetup, s.Setup, stopOnFailure,
+				)
+			})
+		}
+		if setupOK && err == nil {
+			onFailure := func(spec api.Evaluable) api.OnFailure {
+				return s.onFailure(ctx, spec)
 			}
+			ctx, err = s.runGoSpecs(ctx, tt, tt, phaseTest, s.Tests, onFailure)
+		} else {
+			tt.Log("setup failed. skipping tests.")
+		}
+		// Teardown specs are always executed, regardless of whether any
+		// setup spec or test failed or returned a runtime error.
+		if len(s.Teardown) > 0 {
+			tt.Run(phaseTeardown, func(tdt *testing.T) {
+				_, tdErr := s.runGoSpecs(
+					ctx, tt, tdt, phaseTeardown, s.Teardown, continueOnFailure,
+				)
+				if err == nil {
+					err = tdErr
+				}
+			})
+		}
+	}
+	if inSubtest {
+		runScenario(t)
+	} else {
+		t.Run(s.Title(), runScenario)
+	}
+	return err
+}
 
-			for _, cleanup := range res.Cleanups() {
-				t.Cleanup(cleanup)
+// runGoSpecs executes the supplied test specs, in order, using the supplied
+// `*testing.T` to track test run state. Cleanups from the test specs' results
+// are registered with the supplied `*testing.T` of the scenario's own subtest
+// so that they execute, according to the scenario's cleanup policy, as soon as
+// the scenario completes. When a test spec fails, the supplied onFailure func
+// determines whether the remaining test specs are executed. The returned
+// context contains any run data stored in the test specs' results. A runtime
+// error from any test spec stops the execution of the remaining test specs,
+// except during teardown.
+func (s *Scenario) runGoSpecs(
+	ctx context.Context,
+	scenT *testing.T,
+	t *testing.T,
+	phase string,
+	specs []api.Evaluable,
+	onFailure func(api.Evaluable) api.OnFailure,
+) (context.Context, error) {
+	var firstErr error
+	for idx, spec := range specs {
+		res, err := s.runSpec(ctx, phase, idx, spec)
+		if err != nil {
+			if phase != phaseTeardown {
+				return ctx, err
 			}
-
-			// Results can have arbitrary run data stored in them and we
-			// save this prior run data in the top-level context (and pass
-			// that context to the next Run invocation).
-			if res.HasData() {
-				ctx = gdtcontext.SetRun(ctx, res.Data())
+			t.Error(err)
+			if firstErr == nil {
+				firstErr = err
 			}
+			continue
+		}
 
-			for _, fail := range res.Failures() {
-				tt.Fatal(fail)
-			}
+		for _, cleanup := range resultCleanups(res) {
+			scenT.Cleanup(func() {
+				if !s.Cleanup.Applies(scenT.Failed()) {
+					return
+				}
+				if cErr := runCleanup(cleanup); cErr != nil {
+					scenT.Error(cErr)
+				}
+			})
 		}
-	})
-	return err
+
+		// Results can have arbitrary run data stored in them and we save
+		// this prior run data in the top-level context (and pass that context
+		// to the next Run invocation).
+		if res.HasData() {
+			ctx = gdtcontext.SetRun(ctx, res.Data())
+		}
+
+		logHookResults(t, res)
+		for _, fail := range res.Failures() {
+			t.Error(fail)
+		}
+		if res.Failed() && onFailure(spec) == api.OnFailureStop {
+			break
+		}
+	}
+	return ctx, firstErr
+}
+
+// stopOnFailure always returns `api.OnFailureStop`.
+func stopOnFailure(api.Evaluable) api.OnFailure {
+	return api.OnFailureStop
+}
+
+// continueOnFailure always returns `api.OnFailureContinue`.
+func continueOnFailure(api.Evaluable) api.OnFailure {
+	return api.OnFailureContinue
+}
+
+// onFailure returns the policy for whether the scenario's remaining test specs
+// are executed after the supplied test spec fails. We check for overrides in
+// the policy using the following precedence:
+//
+// * Spec's Base override
+// * Scenario's policy (which may have been inherited from a test suite)
+// * The context's policy, set by the test suite running the scenario
+// * `api.OnFailureStop`
+func (s *Scenario) onFailure(
+	ctx context.Context,
+	spec api.Evaluable,
+) api.OnFailure {
+	if of := spec.Base().OnFailure; of != "" {
+		return of
+	}
+	if s.OnFailure != "" {
+		return s.OnFailure
+	}
+	if of := gdtcontext.OnFailure(ctx); of != "" {
+		return of
+	}
+	return api.OnFailureStop
 }
 
 type runSpecRes struct {
@@ -229,8 +523,9 @@ type runSpecRes struct {
 // runSpec wraps the execution of a single test spec
 func (s *Scenario) runSpec(
 	ctx context.Context, // this is the overall scenario's context
-	t api.T, // T specific to the goroutine running this test spec
-	idx int, // index of the test spec within Scenario.Tests
+	phase string, // the phase of the scenario the test spec belongs to
+	idx int, // index of the test spec within the phase's test specs
+	spec api.Evaluable,
 ) (res *api.Result, err error) {
 	// Create a brand new context that inherits the top-level context's
 	// cancel func. We want to set deadlines for each test spec and if
@@ -240,10 +535,12 @@ func (s *Scenario) runSpec(
 	defer specCancel()
 
 	defaults := s.getDefaults()
-	spec := s.Tests[idx]
 	sb := spec.Base()
 
 	specTraceMsg := strconv.Itoa(idx)
+	if phase != phaseTest {
+		specTraceMsg = phase + "/" + specTraceMsg
+	}
 	if sb.Name != "" {
 		specTraceMsg += ":" + sb.Name
 	}
@@ -272,20 +569,89 @@ func (s *Scenario) runSpec(
 
 	select {
 	case <-specCtx.Done():
-		t.Fatalf("assertion failed: timeout exceeded (%s)", to.After)
+		if to == nil {
+			// The overall scenario's context was cancelled.
+			return nil, fmt.Errorf("%w: %w", api.RuntimeError, specCtx.Err())
+		}
+		// A timeout is an assertion failure of the test spec, not a runtime
+		// error, so that the scenario's on-failure policy applies to it.
+		res = api.NewResult(
+			api.WithFailures(api.TimeoutExceeded(to.After, nil)),
+		)
 	case runres := <-ch:
+		if runres.err != nil {
+			return nil, runres.err
+		}
 		res = runres.r
-		err = runres.err
+		if wait != nil && wait.After != "" {
+			debug.Printf(specCtx, "wait: %s after", wait.After)
+			time.Sleep(wait.AfterDuration())
+		}
 	}
-	if err != nil {
-		return nil, err
+	// The test spec's own context may have timed out, so hooks are evaluated
+	// using the overall scenario's context.
+	s.runHooks(gdtcontext.PushTrace(ctx, specTraceMsg), sb, res)
+	return res, nil
+}
+
+// runHooks evaluates the hooks of the supplied test spec that are triggered by
+// the test spec's result, adding the hooks' results to that result. Failures
+// and runtime errors in hooks do not affect the test spec's outcome.
+func (s *Scenario) runHooks(
+	ctx context.Context,
+	sb *api.Spec,
+	res *api.Result,
+) {
+	if sb.On == nil {
+		return
+	}
+	event := api.HookSuccess
+	if res.Failed() {
+		event = api.HookFail
 	}
+	defaults := s.getDefaults()
+	for _, ev := range []api.HookEvent{event, api.HookAlways} {
+		for _, hook := range sb.On.For(ev) {
+			hookCtx := gdtcontext.PushTrace(ctx, "on."+string(ev))
+			hr := api.HookResult{
+				Event: ev,
+				Name:  hook.Base().Title(),
+			}
+			cancel := func() {}
+			to := getTimeout(hookCtx, defaults, hook.Base().Plugin, hook)
+			if to != nil {
+				hookCtx, cancel = context.WithTimeout(hookCtx, to.Duration())
+			}
+			hookRes, err := hook.Eval(hookCtx)
+			cancel()
+			if err != nil {
+				debug.Printf(hookCtx, "error in on.%s: %s", ev, err)
+				hr.Err = err
+			} else {
+				hr.Output = hookRes.Output()
+				hr.Failures = hookRes.Failures()
+			}
+			res.AddHookResult(hr)
+		}
+	}
+}
 
-	if wait != nil && wait.After != "" {
-		debug.Printf(specCtx, "wait: %s after", wait.After)
-		time.Sleep(wait.AfterDuration())
+// logHookResults writes the results of the hooks evaluated after a test spec
+// to the supplied test unit's log.
+func logHookResults(t api.T, res *api.Result) {
+	for _, hr := range res.Hooks() {
+		msg := fmt.Sprintf("on.%s: %s", hr.Event, hr.Name)
+		if hr.Err != nil {
+			msg += fmt.Sprintf(" error: %s", hr.Err)
+		}
+		for _, f := range hr.Failures {
+			msg += fmt.Sprintf("\nfailure: %s", f)
+		}
+		if out := strings.TrimSpace(hr.Output); out != "" {
+			msg += "\n" + out
+		}
+		t.Log(msg)
 	}
-	return res, nil
 }
 
 // execSpec executes an individual test spec, performing any retries as
@@ -16,11 +16,13 @@ import (
 
 	"github.com/gdt-dev/core/api"
 	gdtcontext "github.com/gdt-dev/core/context"
+	"github.com/gdt-dev/core/run"
 	"github.com/gdt-dev/core/scenario"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/gdt-dev/core/internal/testutil/fixture/errstarter"
+	"github.com/gdt-dev/core/internal/testutil/plugin/cleaner"
 )
 
 var failFlag = flag.Bool("fail", false, "run tests expected to fail")
@@ -145,9 +147,81 @@ func TestFixtureStartError(t *testing.T) {
 
 	err = s.Run(ctx, t)
 	assert.NotNil(err)
+	assert.ErrorIs(err, api.ErrFixtureStart)
 	assert.ErrorContains(err, "error starting fixture!")
 }
 
+func TestVarResolveError(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	// The TOKEN variable in vars.yaml refers to a file that does not exist.
+	fp := filepath.Join("testdata", "vars.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.Nil(err)
+	require.NotNil(s)
+
+	r := run.New()
+	err = s.Run(gdtcontext.New(), r)
+	assert.ErrorIs(err, api.ErrVarResolve)
+	assert.ErrorContains(err, "TOKEN")
+	assert.False(r.OK())
+}
+
+func TestFixtureStartErrorExternal(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	fp := filepath.Join("testdata", "fixture-start-error.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.Nil(err)
+	require.NotNil(s)
+
+	ctx := gdtcontext.New()
+	ctx = gdtcontext.RegisterFixture(ctx, "start-error", errstarter.Fixture)
+
+	r := run.New()
+	err = s.Run(ctx, r)
+	assert.ErrorIs(err, api.ErrFixtureStart)
+	assert.False(r.OK())
+
+	errs := r.ScenarioErrors(fp)
+	require.Len(errs, 1)
+	assert.ErrorIs(errs[0], api.ErrFixtureStart)
+	sum := r.Summary()
+	require.Len(sum.Scenarios, 1)
+	assert.Equal(run.StatusErrored, sum.Scenarios[0].Status)
+}
+
+func TestRuntimeErrorExternal(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	fp := filepath.Join("testdata", "runtime-error.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.Nil(err)
+	require.NotNil(s)
+
+	r := run.New()
+	err = s.Run(context.TODO(), r)
+	assert.ErrorIs(err, api.RuntimeError)
+	assert.False(r.OK())
+
+	errs := r.ScenarioErrors(fp)
+	require.Len(errs, 1)
+	assert.ErrorIs(errs[0], api.RuntimeError)
+	assert.Equal([]string{fp}, r.ScenarioPaths())
+}
+
 func TestDebugFlushing(t *testing.T) {
 	require := require.New(t)
 
@@ -271,3 +345,524 @@ func TestSkipIf(t *testing.T) {
 	require.Nil(err)
 	require.True(t.Skipped())
 }
+
+func TestSkipIfExternal(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	fp := filepath.Join("testdata", "skip-if.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.Nil(err)
+	require.NotNil(s)
+
+	r := run.New()
+	err = s.Run(context.TODO(), r)
+	require.Nil(err)
+	assert.True(r.OK())
+
+	reason, skipped := r.ScenarioSkip(fp)
+	assert.True(skipped)
+	assert.Contains(reason, "skip-if")
+	sum := r.Summary()
+	require.Len(sum.Scenarios, 1)
+	assert.Equal(run.StatusSkipped, sum.Scenarios[0].Status)
+	assert.Equal(reason, sum.Scenarios[0].SkipReason)
+}
+
+func TestRunDoesNotChangeWorkingDirectory(t *testing.T) {
+	require := require.New(t)
+
+	cwd, err := os.Getwd()
+	require.Nil(err)
+
+	fp := filepath.Join("testdata", "foo.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.Nil(err)
+	require.NotNil(s)
+
+	absDir, err := filepath.Abs("testdata")
+	require.Nil(err)
+	require.Equal(absDir, s.BaseDir)
+
+	err = s.Run(context.TODO(), t)
+	require.Nil(err)
+
+	after, err := os.Getwd()
+	require.Nil(err)
+	require.Equal(cwd, after)
+}
+
+func TestOnFailureStopExternal(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	fp := filepath.Join("testdata", "on-failure-stop.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.Nil(err)
+	require.NotNil(s)
+
+	r := run.New()
+	err = s.Run(context.TODO(), r)
+	require.Nil(err)
+	assert.False(r.OK())
+
+	results := r.ScenarioResults(fp)
+	require.Len(results, 2)
+	assert.False(results[0].OK())
+	assert.True(results[1].Skipped())
+}
+
+func TestOnFailureContinueExternal(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	fp := filepath.Join("testdata", "on-failure-continue.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.Nil(err)
+	require.NotNil(s)
+	assert.Equal(api.OnFailureContinue, s.OnFailure)
+
+	r := run.New()
+	err = s.Run(context.TODO(), r)
+	require.Nil(err)
+	assert.False(r.OK())
+
+	results := r.ScenarioResults(fp)
+	require.Len(results, 2)
+	assert.False(results[0].OK())
+	assert.False(results[1].Skipped())
+	assert.True(results[1].OK())
+}
+
+func TestOnFailureSpecOverrideExternal(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	fp := filepath.Join("testdata", "on-failure-spec-override.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.Nil(err)
+	require.NotNil(s)
+
+	r := run.New()
+	err = s.Run(context.TODO(), r)
+	require.Nil(err)
+
+	results := r.ScenarioResults(fp)
+	require.Len(results, 3)
+	assert.True(results[0].OK())
+	assert.False(results[1].OK())
+	assert.True(results[2].Skipped())
+}
+
+func TestFailOnFailureContinue(t *testing.T) {
+	if !*failFlag {
+		t.Skip("skipping without -fail flag")
+	}
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "on-failure-continue.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.Nil(err)
+	require.NotNil(s)
+
+	ctx := gdtcontext.New(gdtcontext.WithDebug())
+	err = s.Run(ctx, t)
+	require.Nil(err)
+}
+
+func TestOnFailureContinue(t *testing.T) {
+	require := require.New(t)
+	target := os.Args[0]
+	failArgs := []string{
+		"-test.v",
+		"-test.run=FailOnFailureContinue",
+		"-fail",
+	}
+	outerr, err := exec.Command(target, failArgs...).CombinedOutput()
+
+	// The test should have failed...
+	require.NotNil(err)
+
+	// ... but the test spec after the failing one should still have run.
+	debugout := string(outerr)
+	require.Contains(debugout, "[gdt] [on-failure-continue/1:bar] spec/run: single-shot (no retries) ok: true")
+}
+
+func TestSetupTeardown(t *testing.T) {
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "setup-teardown.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.Nil(err)
+	require.NotNil(s)
+	require.Len(s.Setup, 1)
+	require.Len(s.Tests, 2)
+	require.Len(s.Teardown, 1)
+
+	err = s.Run(context.TODO(), t)
+	require.Nil(err)
+	require.False(t.Failed())
+}
+
+func TestSetupTeardownExternal(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	fp := filepath.Join("testdata", "setup-teardown.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.Nil(err)
+	require.NotNil(s)
+
+	r := run.New()
+	err = s.Run(context.TODO(), r)
+	require.Nil(err)
+	assert.True(r.OK())
+
+	setup := r.ScenarioSetupResults(fp)
+	require.Len(setup, 1)
+	assert.Equal("setup-teardown/setup/bar", setup[0].Name())
+	assert.Len(r.ScenarioResults(fp), 2)
+	teardown := r.ScenarioTeardownResults(fp)
+	require.Len(teardown, 1)
+	assert.Equal("setup-teardown/teardown/bar", teardown[0].Name())
+	assert.Equal(2, r.Summary().Passed)
+}
+
+func TestSetupFailExternal(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	fp := filepath.Join("testdata", "setup-fail.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.Nil(err)
+	require.NotNil(s)
+
+	r := run.New()
+	err = s.Run(context.TODO(), r)
+	require.Nil(err)
+	assert.False(r.OK())
+
+	setup := r.ScenarioSetupResults(fp)
+	require.Len(setup, 1)
+	assert.False(setup[0].OK())
+	results := r.ScenarioResults(fp)
+	require.Len(results, 1)
+	assert.True(results[0].Skipped())
+	teardown := r.ScenarioTeardownResults(fp)
+	require.Len(teardown, 1)
+	assert.True(teardown[0].OK())
+
+	sum := r.Summary()
+	assert.Equal(1, sum.SetupFailed)
+	require.Len(sum.Scenarios, 1)
+	assert.Equal(run.StatusFailed, sum.Scenarios[0].Status)
+}
+
+func TestTeardownAfterFailureExternal(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	fp := filepath.Join("testdata", "teardown-after-failure.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.Nil(err)
+	require.NotNil(s)
+
+	r := run.New()
+	err = s.Run(context.TODO(), r)
+	require.Nil(err)
+	assert.False(r.OK())
+
+	// A failing teardown spec does not prevent the remaining teardown specs
+	// from executing.
+	teardown := r.ScenarioTeardownResults(fp)
+	require.Len(teardown, 2)
+	assert.False(teardown[0].OK())
+	assert.True(teardown[1].OK())
+	assert.Equal(1, r.Summary().TeardownFailed)
+}
+
+func TestTeardownAfterErrorExternal(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	fp := filepath.Join("testdata", "teardown-after-error.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.Nil(err)
+	require.NotNil(s)
+
+	r := run.New()
+	err = s.Run(context.TODO(), r)
+	assert.ErrorIs(err, api.RuntimeError)
+
+	teardown := r.ScenarioTeardownResults(fp)
+	require.Len(teardown, 1)
+	assert.True(teardown[0].OK())
+}
+
+func TestFailSetupFail(t *testing.T) {
+	if !*failFlag {
+		t.Skip("skipping without -fail flag")
+	}
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "setup-fail.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.Nil(err)
+	require.NotNil(s)
+
+	ctx := gdtcontext.New(gdtcontext.WithDebug())
+	err = s.Run(ctx, t)
+	require.Nil(err)
+}
+
+func TestSetupFail(t *testing.T) {
+	require := require.New(t)
+	target := os.Args[0]
+	failArgs := []string{
+		"-test.v",
+		"-test.run=FailSetupFail",
+		"-fail",
+	}
+	outerr, err := exec.Command(target, failArgs...).CombinedOutput()
+
+	// The test should have failed...
+	require.NotNil(err)
+
+	// ... without running the scenario's tests but still running the
+	// scenario's teardown specs.
+	debugout := string(outerr)
+	require.Contains(debugout, "--- FAIL: TestFailSetupFail/setup-fail/setup")
+	require.NotContains(debugout, "[gdt] [setup-fail/0:bar]")
+	require.Contains(debugout, "[gdt] [setup-fail/teardown/0:bar] spec/run: single-shot (no retries) ok: true")
+}
+
+func TestCleanupPolicyExternal(t *testing.T) {
+	tests := []struct {
+		fname string
+		ran   []string
+	}{
+		{"cleanup-always.yaml", []string{"second", "first"}},
+		{"cleanup-on-success.yaml", nil},
+		{"cleanup-on-failure.yaml", nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.fname, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+
+			fp := filepath.Join("testdata", tc.fname)
+			f, err := os.Open(fp)
+			require.Nil(err)
+
+			s, err := scenario.FromReader(f, scenario.WithPath(fp))
+			require.Nil(err)
+			require.NotNil(s)
+
+			cleaner.Reset()
+			r := run.New()
+			err = s.Run(context.TODO(), r)
+			require.Nil(err)
+			assert.Equal(tc.ran, cleaner.Ran())
+		})
+	}
+}
+
+func TestCleanupPolicyGo(t *testing.T) {
+	tests := []struct {
+		fname  string
+		policy api.CleanupPolicy
+		ran    []string
+	}{
+		{"cleanup-on-failure.yaml", "", nil},
+		{"cleanup-on-failure.yaml", api.CleanupAlways, []string{"first"}},
+		{"cleanup-on-failure.yaml", api.CleanupOnSuccess, []string{"first"}},
+	}
+	for _, tc := range tests {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		fp := filepath.Join("testdata", tc.fname)
+		f, err := os.Open(fp)
+		require.Nil(err)
+
+		s, err := scenario.FromReader(f, scenario.WithPath(fp))
+		require.Nil(err)
+		require.NotNil(s)
+		if tc.policy != "" {
+			s.Cleanup = tc.policy
+		}
+
+		cleaner.Reset()
+		// Cleanups are registered with the scenario's own subtest and
+		// therefore executed by the time Run returns.
+		err = s.Run(context.TODO(), t)
+		require.Nil(err)
+		assert.Equal(tc.ran, cleaner.Ran())
+	}
+}
+
+func TestFailCleanupPolicySharedT(t *testing.T) {
+	if !*failFlag {
+		t.Skip("skipping without -fail flag")
+	}
+	require := require.New(t)
+
+	cleaner.Reset()
+	fnames := []string{
+		"cleanup-always.yaml",
+		"cleanup-on-failure.yaml",
+		"cleanup-on-success-pass.yaml",
+	}
+	for _, fname := range fnames {
+		fp := filepath.Join("testdata", fname)
+		f, err := os.Open(fp)
+		require.Nil(err)
+
+		s, err := scenario.FromReader(f, scenario.WithPath(fp))
+		require.Nil(err)
+		require.NotNil(s)
+
+		err = s.Run(context.TODO(), t)
+		require.Nil(err)
+		t.Logf("cleanups after %s: %v", fname, cleaner.Ran())
+	}
+}
+
+func TestCleanupPolicySharedT(t *testing.T) {
+	require := require.New(t)
+	target := os.Args[0]
+	failArgs := []string{
+		"-test.v",
+		"-test.run=FailCleanupPolicySharedT",
+		"-fail",
+	}
+	outerr, err := exec.Command(target, failArgs...).CombinedOutput()
+
+	// The test should have failed because of the cleanup-always scenario...
+	require.NotNil(err)
+
+	// ... with each scenario's cleanups executed when that scenario
+	// completes, according to whether that scenario, not the enclosing
+	// test, failed.
+	debugout := string(outerr)
+	require.Contains(debugout, "cleanups after cleanup-always.yaml: [second first]")
+	require.Contains(debugout, "cleanups after cleanup-on-failure.yaml: [second first]")
+	require.Contains(debugout, "cleanups after cleanup-on-success-pass.yaml: [second first passed]")
+}
+
+func TestCleanupErrorsExternal(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	fp := filepath.Join("testdata", "cleanup-errors.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.Nil(err)
+	require.NotNil(s)
+
+	cleaner.Reset()
+	r := run.New()
+	err = s.Run(context.TODO(), r)
+	require.Nil(err)
+
+	// A cleanup that returns an error or panics does not prevent the
+	// remaining cleanups from executing.
+	assert.Equal([]string{"third", "second", "first"}, cleaner.Ran())
+
+	errs := r.ScenarioErrors(fp)
+	require.Len(errs, 2)
+	for _, err := range errs {
+		assert.ErrorIs(err, api.ErrCleanup)
+		assert.ErrorIs(err, api.RuntimeError)
+	}
+	assert.ErrorContains(errs[0], "panic: second cleanup panic")
+	assert.ErrorContains(errs[1], "first cleanup error")
+	assert.False(r.OK())
+}
+
+func TestHooksExternal(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	fp := filepath.Join("testdata", "hooks.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.Nil(err)
+	require.NotNil(s)
+
+	r := run.New()
+	err = s.Run(context.TODO(), r)
+	require.Nil(err)
+	assert.False(r.OK())
+
+	results := r.ScenarioResults(fp)
+	require.Len(results, 2)
+
+	// Hook failures do not affect the outcome of the test spec.
+	passes := results[0]
+	assert.True(passes.OK())
+	hooks := passes.Hooks()
+	require.Len(hooks, 2)
+	assert.Equal(api.HookSuccess, hooks[0].Event)
+	assert.Equal("success-hook", hooks[0].Name)
+	assert.Equal(api.HookAlways, hooks[1].Event)
+	assert.Equal("always-hook", hooks[1].Name)
+	assert.Contains(passes.Detail(), "on.success: success-hook")
+	assert.Contains(passes.Detail(), "on.always: always-hook")
+
+	fails := results[1]
+	assert.False(fails.OK())
+	require.Len(fails.Failures(), 1)
+	hooks = fails.Hooks()
+	require.Len(hooks, 3)
+	assert.Equal(api.HookFail, hooks[0].Event)
+	assert.Equal("fail-hook", hooks[0].Name)
+	assert.Empty(hooks[0].Failures)
+	assert.Equal(api.HookFail, hooks[1].Event)
+	assert.Equal("failing-fail-hook", hooks[1].Name)
+	require.Len(hooks[1].Failures, 1)
+	assert.ErrorContains(hooks[1].Failures[0], "failing-fail-hook failed")
+	assert.Equal(api.HookAlways, hooks[2].Event)
+	assert.Equal("always-hook", hooks[2].Name)
+	assert.Contains(fails.Detail(), "failure: failing-fail-hook failed")
+}
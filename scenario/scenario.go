@@ -6,8 +6,10 @@ package scenario
 
 import (
 	gopath "path"
+	"path/filepath"
 
 	"github.com/gdt-dev/core/api"
+	"github.com/gdt-dev/core/parse"
 )
 
 // Scenario is a generalized gdt test case file. It contains a set of Runnable
@@ -17,6 +19,14 @@ type Scenario struct {
 	Timings *api.Timings `yaml:"-"`
 	// Path is the filepath to the test scenario YAML file.
 	Path string `yaml:"-"`
+	// BaseDir is the directory that relative file paths referenced within the
+	// test scenario are resolved against. If empty, defaults to the directory
+	// containing the file at Path.
+	//
+	// The BaseDir is placed in the context handed to plugins (see
+	// `gdtcontext.BaseDir`) when the scenario is run so that no process-wide
+	// working directory change is ever needed.
+	BaseDir string `yaml:"-"`
 	// Name is the short name for the test case. If empty, defaults to the base
 	// filename in Path.
 	Name string `yaml:"name,omitempty"`
@@ -30,6 +40,21 @@ type Scenario struct {
 	Defaults map[string]interface{} `yaml:"defaults,omitempty"`
 	// Fixtures specifies an ordered list of fixtures the test case depends on.
 	Fixtures []string `yaml:"fixtures,omitempty"`
+	// Vars contains variables that are resolved when the scenario is run and
+	// seeded into the context's run data before the first test spec, so that
+	// any test spec may refer to them, e.g. `$${NAME}`. Variables declared
+	// here override variables of the same name declared by an enclosing test
+	// suite.
+	Vars api.Vars `yaml:"vars,omitempty"`
+	// OnFailure is the policy for whether the scenario's remaining test specs
+	// are executed after a test spec fails. Individual test specs may
+	// override the policy with their own `on-failure` field. If empty,
+	// defaults to `api.OnFailureStop`.
+	OnFailure api.OnFailure `yaml:"on-failure,omitempty"`
+	// Cleanup is the policy for when the cleanup functions registered by the
+	// scenario's test specs are executed. If empty, defaults to
+	// `api.CleanupAlways`.
+	Cleanup api.CleanupPolicy `yaml:"cleanup,omitempty"`
 	// SkipIf contains a list of evaluable conditions. If any of the conditions
 	// evaluates successfully, the test scenario will be skipped.  This allows
 	// test authors to specify "pre-flight checks" that should pass before
@@ -72,9 +97,33 @@ type Scenario struct {
 	// With the above, if an 'nginx' deployment exists already, the scenario
 	// will skip all the tests.
 	SkipIf []api.Evaluable `yaml:"skip-if,omitempty"`
+	// Setup is a collection of test specs that are executed, in order, before
+	// any of the scenario's tests. If any setup spec fails, none of the
+	// scenario's tests are executed. Setup specs are not test units and their
+	// results are reported separately.
+	Setup []api.Evaluable `yaml:"setup,omitempty"`
 	// Tests is the collection of test units in this test case. These will be
 	// the fully parsed and materialized plugin Spec structs.
 	Tests []api.Evaluable `yaml:"tests,omitempty"`
+	// Teardown is a collection of test specs that are executed, in order,
+	// after the scenario's tests. Teardown specs are always executed, even if
+	// a setup spec or test failed, timed out or returned a runtime error, and
+	// a failing teardown spec does not prevent the remaining teardown specs
+	// from executing. Their results are reported separately from the
+	// scenario's test units.
+	Teardown []api.Evaluable `yaml:"teardown,omitempty"`
+
+	// inheritedDefaults contains raw default configuration values inherited
+	// from an enclosing test suite. They are merged underneath the
+	// scenario's own `defaults` during parsing, so that values in the
+	// scenario override values in the suite.
+	inheritedDefaults map[string]interface{}
+	// expandOpts configures the expansion of environment variable references
+	// in the scenario's contents.
+	expandOpts []parse.ExpandOption
+	// validateSchema is true when the scenario's contents are validated
+	// against the Schema of the registered plugins before being parsed.
+	validateSchema bool
 }
 
 // Title returns the Name of the scenario or the Path's file/base name if there
@@ -103,6 +152,13 @@ func WithPath(path string) ScenarioModifier {
 	}
 }
 
+// WithBaseDir sets a test scenario's BaseDir attribute
+func WithBaseDir(dir string) ScenarioModifier {
+	return func(s *Scenario) {
+		s.BaseDir = dir
+	}
+}
+
 // WithDescription sets a test scenario's Description attribute
 func WithDescription(description string) ScenarioModifier {
 	return func(s *Scenario) {
@@ -117,6 +173,54 @@ func WithDefaults(defaults map[string]interface{}) ScenarioModifier {
 	}
 }
 
+// WithVars sets a test scenario's Vars attribute
+func WithVars(vars api.Vars) ScenarioModifier {
+	return func(s *Scenario) {
+		s.Vars = vars
+	}
+}
+
+// WithOnFailure sets a test scenario's OnFailure attribute
+func WithOnFailure(onFailure api.OnFailure) ScenarioModifier {
+	return func(s *Scenario) {
+		s.OnFailure = onFailure
+	}
+}
+
+// WithCleanup sets a test scenario's Cleanup attribute
+func WithCleanup(cleanup api.CleanupPolicy) ScenarioModifier {
+	return func(s *Scenario) {
+		s.Cleanup = cleanup
+	}
+}
+
+// WithInheritedDefaults sets the raw default configuration values the test
+// scenario inherits from an enclosing test suite. Any values in the scenario's
+// own `defaults` field override the inherited values.
+func WithInheritedDefaults(defaults map[string]interface{}) ScenarioModifier {
+	return func(s *Scenario) {
+		s.inheritedDefaults = defaults
+	}
+}
+
+// WithEnvExpansion sets the options used when expanding references to
+// environment variables in the test scenario's contents, e.g.
+// `parse.WithStrictEnv(true)`.
+func WithEnvExpansion(opts ...parse.ExpandOption) ScenarioModifier {
+	return func(s *Scenario) {
+		s.expandOpts = append(s.expandOpts, opts...)
+	}
+}
+
+// WithSchemaValidation sets whether the test scenario's contents are
+// validated against the JSON Schema of the registered plugins (see Schema)
+// before being parsed.
+func WithSchemaValidation(validate bool) ScenarioModifier {
+	return func(s *Scenario) {
+		s.validateSchema = validate
+	}
+}
+
 // WithFixtures sets a test scenario's Fixtures attribute
 func WithRequires(fixtures []string) ScenarioModifier {
 	return func(s *Scenario) {
@@ -132,5 +236,12 @@ func New(mods ...ScenarioModifier) *Scenario {
 	for _, mod := range mods {
 		mod(s)
 	}
+	if s.BaseDir == "" && s.Path != "" {
+		dir := filepath.Dir(s.Path)
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+		s.BaseDir = dir
+	}
 	return s
 }
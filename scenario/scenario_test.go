@@ -19,6 +19,8 @@ func TestConstructor(t *testing.T) {
 	)
 
 	assert.Equal("/path/to/foo.yaml", s.Path)
+	// BaseDir defaults to the directory containing the scenario file
+	assert.Equal("/path/to", s.BaseDir)
 	assert.Equal("", s.Name)
 	// Title() returns the basename from the path if name isn't present
 	assert.Equal("foo.yaml", s.Title())
@@ -26,3 +28,14 @@ func TestConstructor(t *testing.T) {
 	s.Name = "foo"
 	assert.Equal("foo", s.Title())
 }
+
+func TestBaseDirOverride(t *testing.T) {
+	assert := assert.New(t)
+
+	s := scenario.New(
+		scenario.WithPath("/path/to/foo.yaml"),
+		scenario.WithBaseDir("/other/dir"),
+	)
+
+	assert.Equal("/other/dir", s.BaseDir)
+}
@@ -0,0 +1,245 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	gjs "github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
+
+	"github.com/gdt-dev/core/api"
+	"github.com/gdt-dev/core/parse"
+	"github.com/gdt-dev/core/plugin"
+)
+
+const (
+	// schemaDraft is the JSON Schema draft that Schema documents conform to.
+	schemaDraft = "http://json-schema.org/draft-07/schema#"
+	// specRef refers to the definition of a test spec handled by any plugin.
+	specRef = "#/definitions/spec"
+	// schemaContextSeparator separates the elements of the path to a field in
+	// schema validation errors. Map keys may contain dots, so we can't use the
+	// default separator.
+	schemaContextSeparator = "\x00"
+)
+
+// Schema returns a JSON Schema document describing test scenario files whose
+// test specs are handled by the supplied plugins. If no plugins are supplied,
+// the registered plugins are used.
+//
+// Plugins implementing api.SchemaProvider contribute the schemas of their test
+// specs and defaults. Test specs of other plugins are accepted as any map.
+func Schema(plugins ...api.Plugin) api.Schema {
+	if len(plugins) == 0 {
+		plugins = plugin.Registered()
+	}
+	plugins = slices.SortedFunc(
+		slices.Values(plugins),
+		func(a, b api.Plugin) int {
+			return strings.Compare(a.Info().Name, b.Info().Name)
+		},
+	)
+	hookSpec := api.Schema{"$ref": specRef}
+	defs := api.Schema{}
+	anyOf := []any{}
+	defaults := api.Schema{
+		"timeout": api.TimeoutSchema,
+		"retry":   api.RetrySchema,
+	}
+	for _, p := range plugins {
+		name := "spec." + p.Info().Name
+		spec := api.Schema{"type": "object"}
+		if sp, ok := p.(api.SchemaProvider); ok {
+			spec = pluginSpecSchema(sp.SpecSchema(), hookSpec)
+			maps.Copy(defaults, sp.DefaultsSchema())
+		}
+		defs[name] = spec
+		anyOf = append(anyOf, api.Schema{"$ref": "#/definitions/" + name})
+	}
+	if len(anyOf) == 0 {
+		defs["spec"] = api.Schema{"type": "object"}
+	} else {
+		defs["spec"] = api.Schema{"anyOf": anyOf}
+	}
+	specs := api.Schema{
+		"type":  "array",
+		"items": hookSpec,
+	}
+	return api.Schema{
+		"$schema":     schemaDraft,
+		"title":       "gdt test scenario",
+		"type":        "object",
+		"definitions": defs,
+		"properties": api.Schema{
+			"name":        api.Schema{"type": "string"},
+			"description": api.Schema{"type": "string"},
+			"fixtures": api.Schema{
+				"type":  "array",
+				"items": api.Schema{"type": "string"},
+			},
+			"on-failure": api.OnFailureSchema,
+			"cleanup": api.Schema{
+				"type": "string",
+				"enum": []any{
+					string(api.CleanupAlways),
+					string(api.CleanupOnSuccess),
+					string(api.CleanupOnFailure),
+				},
+			},
+			"defaults": api.Schema{
+				"type":       "object",
+				"properties": defaults,
+			},
+			"vars": api.Schema{
+				"type":                 "object",
+				"additionalProperties": varSchema,
+			},
+			"skip-if":  specs,
+			"setup":    specs,
+			"tests":    specs,
+			"teardown": specs,
+		},
+	}
+}
+
+// SchemaJSON returns the Schema document for the supplied plugins, or the
+// registered plugins if none are supplied, encoded as indented JSON. The
+// document may be saved to a file and referenced from editor configuration,
+// e.g. a `# yaml-language-server: $schema=gdt.schema.json` comment.
+func SchemaJSON(plugins ...api.Plugin) ([]byte, error) {
+	return json.MarshalIndent(Schema(plugins...), "", "  ")
+}
+
+// varSchema is the JSON Schema of a variable in a scenario's `vars` field. A
+// variable that is not a map is a literal value.
+var varSchema = api.Schema{
+	"if": api.Schema{"type": "object"},
+	"then": api.Schema{
+		"properties": api.Schema{
+			"value":   true,
+			"env":     api.Schema{"type": "string"},
+			"default": true,
+			"file":    api.Schema{"type": "string"},
+			"format": api.Schema{
+				"type": "string",
+				"enum": []any{
+					api.VarFormatText,
+					api.VarFormatJSON,
+					api.VarFormatYAML,
+				},
+			},
+		},
+		"additionalProperties": false,
+	},
+}
+
+// pluginSpecSchema returns a copy of the supplied plugin test spec schema
+// with the base test spec fields added to its properties. Unless the plugin
+// says otherwise, fields that are neither base nor plugin fields are not
+// allowed.
+func pluginSpecSchema(spec api.Schema, hookSpec api.Schema) api.Schema {
+	res := maps.Clone(spec)
+	if res == nil {
+		res = api.Schema{}
+	}
+	props := api.BaseSpecSchema(hookSpec)
+	if specProps, ok := spec["properties"].(api.Schema); ok {
+		maps.Copy(props, specProps)
+	}
+	res["type"] = "object"
+	res["properties"] = props
+	if _, ok := res["additionalProperties"]; !ok {
+		res["additionalProperties"] = false
+	}
+	return res
+}
+
+// validateSchema validates the supplied scenario document node against the
+// Schema of the registered plugins, returning a *parse.Error that describes
+// all violations and is located at the most specific invalid field.
+func validateSchema(doc *yaml.Node) error {
+	var contents any
+	if err := doc.Decode(&contents); err != nil {
+		return err
+	}
+	res, err := gjs.Validate(
+		gjs.NewGoLoader(Schema()),
+		gjs.NewGoLoader(contents),
+	)
+	if err != nil {
+		return err
+	}
+	if res.Valid() {
+		return nil
+	}
+	msgs := []string{}
+	for _, re := range res.Errors() {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", re.Field(), re.Description()))
+	}
+	// The error with the longest path is the most specific, e.g. an invalid
+	// assertion field rather than the test spec that no plugin's schema
+	// matched.
+	var path []string
+	for _, re := range res.Errors() {
+		p := strings.Split(
+			re.Context().String(schemaContextSeparator),
+			schemaContextSeparator,
+		)
+		if prop, ok := re.Details()["property"].(string); ok {
+			p = append(p, prop)
+		}
+		if len(p) > len(path) {
+			path = p
+		}
+	}
+	return parse.SchemaViolationAt(
+		nodeAtPath(doc, path[1:]),
+		strings.Join(msgs, "\n"),
+	)
+}
+
+// nodeAtPath returns the deepest node in the supplied document that is found
+// along the supplied path of map keys and sequence indexes. For map keys, the
+// key node is returned.
+func nodeAtPath(doc *yaml.Node, path []string) *yaml.Node {
+	node := doc
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	found := node
+	for _, elem := range path {
+		switch node.Kind {
+		case yaml.MappingNode:
+			var next *yaml.Node
+			for i := 0; i < len(node.Content); i += 2 {
+				if node.Content[i].Value == elem {
+					found = node.Content[i]
+					next = node.Content[i+1]
+					break
+				}
+			}
+			if next == nil {
+				return found
+			}
+			node = next
+		case yaml.SequenceNode:
+			idx, err := strconv.Atoi(elem)
+			if err != nil || idx < 0 || idx >= len(node.Content) {
+				return found
+			}
+			node = node.Content[idx]
+			found = node
+		default:
+			return found
+		}
+	}
+	return found
+}
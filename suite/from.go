@@ -5,19 +5,58 @@
 package suite
 
 import (
+	"bufio"
+	"errors"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 
+	"github.com/gdt-dev/core/api"
 	"github.com/gdt-dev/core/scenario"
 	"github.com/samber/lo"
 )
 
+const (
+	// IgnoreFileName is the name of the file that, when present in a suite
+	// directory, contains glob patterns of files and directories that should
+	// not be considered when discovering test scenarios. Patterns apply to the
+	// directory containing the ignore file and all of its subdirectories.
+	IgnoreFileName = ".gdtignore"
+)
+
 var (
 	validFileExts = []string{".yaml", ".yml"}
 )
 
 // FromDir reads the supplied directory path and returns a Suite representing
 // the suite of test scenarios in that directory.
+//
+// By default, the YAML files in the directory and all of its subdirectories
+// are returned as a single, flat list of scenarios in `Suite.Scenarios`. Use
+// `WithRecursive(true)` to instead return the scenarios in subdirectories as
+// nested suites in `Suite.Suites` mirroring the directory tree, or
+// `WithRecursive(false)` to only consider the YAML files directly within the
+// directory. `WithInclude()` and `WithExclude()` filter the files and
+// directories that are considered and any `.gdtignore` files found along the
+// way are honoured.
+//
+// YAML files that don't contain any tests, e.g. because they aren't test
+// scenarios, are skipped and an `api.ErrNoTests` for each of them is added to
+// `Suite.Warnings`. Parsing stops at the first scenario file that fails to
+// parse unless `WithCollectErrors(true)` is supplied, in which case all files
+// are parsed and the errors from every failed file are returned together.
+//
+// A directory may contain a `suite.yaml` (or `_suite.yaml`) manifest that
+// declares the name, description, concurrency, on-failure policy, defaults and
+// fixtures of the test suite for that directory. The suite's on-failure
+// policy, defaults and fixtures are merged into every scenario in the
+// directory and its nested suites, with values in the scenario (or nested
+// suite) overriding values in the enclosing suite. When subdirectories are not
+// returned as nested suites, the variables in a subdirectory's manifest are
+// added to each of the subdirectory's scenarios and its concurrency is
+// ignored.
+// Manifest values override those supplied via SuiteModifiers.
 func FromDir(
 	dirPath string,
 	mods ...SuiteModifier,
@@ -33,47 +72,267 @@ func FromDir(
 	// List YAML files in the directory and parse each into a testable unit
 	mods = append(mods, WithPath(absPath))
 	s := New(mods...)
+	s.root = absPath
 
-	// Need to chdir here so that test scenarios may reference files in
-	// relative directories
-	if err := os.Chdir(absPath); err != nil {
+	errs := []error{}
+	warns := []error{}
+	if err := s.load(absPath, nil, &errs, &warns); err != nil {
 		return nil, err
 	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if len(warns) > 0 {
+		s.Warnings = warns
+	}
+	return s, nil
+}
 
-	if err := filepath.Walk(
-		absPath,
-		func(path string, info os.FileInfo, _ error) error {
-			if info.IsDir() {
-				// We only go one level deep.
-				return nil
-			}
-			suffix := filepath.Ext(path)
-			if !lo.Contains(validFileExts, suffix) {
-				return nil
-			}
+// ignorePattern is a glob pattern read from a `.gdtignore` file.
+type ignorePattern struct {
+	// dir is the absolute path of the directory containing the ignore file.
+	dir string
+	// glob is the glob pattern to match against.
+	glob string
+	// dirOnly is true when the pattern only applies to directories (the
+	// pattern ended with a "/" in the ignore file).
+	dirOnly bool
+}
 
-			f, err := os.Open(path)
-			if err != nil {
-				return err
-			}
-			defer f.Close()
+// matches returns true if the supplied absolute filepath is matched by the
+// ignore pattern.
+func (p ignorePattern) matches(fp string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	rel, err := filepath.Rel(p.dir, fp)
+	if err != nil {
+		return false
+	}
+	return matchesGlob(p.glob, filepath.ToSlash(rel))
+}
 
-			tc, err := scenario.FromReader(f, scenario.WithPath(path))
-			if err != nil {
+// matchesGlob returns true if the supplied slash-separated relative path or
+// its base name matches the supplied glob pattern.
+func matchesGlob(glob string, rel string) bool {
+	if ok, _ := path.Match(glob, rel); ok {
+		return true
+	}
+	ok, _ := path.Match(glob, path.Base(rel))
+	return ok
+}
+
+// readIgnoreFile returns the ignore patterns in the `.gdtignore` file in the
+// supplied directory, if any.
+func readIgnoreFile(dir string) ([]ignorePattern, error) {
+	f, err := os.Open(filepath.Join(dir, IgnoreFileName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	patterns := []ignorePattern{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p := ignorePattern{dir: dir}
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		p.glob = strings.TrimPrefix(line, "/")
+		patterns = append(patterns, p)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return patterns, nil
+}
+
+// anchorVars returns a copy of the supplied variables with any relative file
+// paths resolved against the supplied directory, so that the variables may be
+// resolved from a scenario in a different directory.
+func anchorVars(vars api.Vars, dir string) api.Vars {
+	res := make(api.Vars, 0, len(vars))
+	for _, va := range vars {
+		if va.File != "" && !filepath.IsAbs(va.File) {
+			anchored := *va
+			anchored.File = filepath.Join(dir, va.File)
+			va = &anchored
+		}
+		res = append(res, va)
+	}
+	return res
+}
+
+// load parses the scenario files in the supplied directory into the Suite
+// and, depending on the Suite's discovery, adds the scenarios in
+// subdirectories to the Suite or creates nested suites for them.
+// Errors parsing individual scenario files are appended to the supplied errs
+// collection when the suite collects errors, otherwise they are returned
+// immediately. YAML files without any tests are appended to the supplied
+// warns collection.
+func (s *Suite) load(
+	dir string,
+	ignores []ignorePattern,
+	errs *[]error,
+	warns *[]error,
+) error {
+	dirIgnores, err := readIgnoreFile(dir)
+	if err != nil {
+		return err
+	}
+	ignores = append(ignores[:len(ignores):len(ignores)], dirIgnores...)
+
+	m, _, err := manifestFromDir(dir, s.expandOpts...)
+	if err != nil {
+		if s.collectErrors {
+			// None of the directory's scenarios can be reliably parsed
+			// without the suite's defaults, so skip the directory.
+			*errs = append(*errs, err)
+			return nil
+		}
+		return err
+	}
+	if m != nil {
+		s.applyManifest(m)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		fp := filepath.Join(dir, entry.Name())
+		isDir := entry.IsDir()
+		if s.ignored(fp, isDir, ignores) {
+			continue
+		}
+		if isDir {
+			if s.discovery == discoverTopLevel {
+				continue
+			}
+			child := s.child(fp)
+			if err := child.load(fp, ignores, errs, warns); err != nil {
 				return err
 			}
-			if len(tc.Tests) == 0 {
-				// Either wasn't a test scenario or didn't have any tests in
-				// it, so ignore...
-				return nil
+			if s.discovery == discoverFlat {
+				for _, sc := range child.Scenarios {
+					sc.Vars = append(anchorVars(child.Vars, fp), sc.Vars...)
+					s.Append(sc)
+				}
+				continue
 			}
-			s.Append(tc)
-			return nil
-		},
-	); err != nil {
+			if len(child.Scenarios) > 0 || len(child.Suites) > 0 {
+				s.Suites = append(s.Suites, child)
+			}
+			continue
+		}
+		if !lo.Contains(validFileExts, filepath.Ext(fp)) {
+			continue
+		}
+		if lo.Contains(manifestFileNames, entry.Name()) {
+			continue
+		}
+		if len(s.include) > 0 && !s.matchesAny(s.include, fp) {
+			continue
+		}
+
+		tc, err := s.scenarioFromFile(fp)
+		if err != nil {
+			if s.collectErrors {
+				*errs = append(*errs, err)
+				continue
+			}
+			return err
+		}
+		if len(tc.Tests) == 0 {
+			// Either wasn't a test scenario or didn't have any tests in
+			// it, so skip it, but let the user know.
+			*warns = append(*warns, api.NoTests(fp))
+			continue
+		}
+		s.Append(tc)
+	}
+	return nil
+}
+
+// scenarioFromFile parses the scenario in the file at the supplied path. The
+// scenario inherits the Suite's on-failure policy, defaults and fixtures.
+func (s *Suite) scenarioFromFile(fp string) (*scenario.Scenario, error) {
+	f, err := os.Open(fp)
+	if err != nil {
 		return nil, err
 	}
-	return s, nil
+	defer f.Close()
+
+	sc, err := scenario.FromReader(
+		f,
+		scenario.WithPath(fp),
+		scenario.WithOnFailure(s.OnFailure),
+		scenario.WithInheritedDefaults(s.Defaults),
+		scenario.WithEnvExpansion(s.expandOpts...),
+		scenario.WithSchemaValidation(s.validateSchema),
+	)
+	if err != nil {
+		return nil, err
+	}
+	sc.Fixtures = mergeFixtures(s.Fixtures, sc.Fixtures)
+	return sc, nil
+}
+
+// ignored returns true if the supplied absolute file or directory path should
+// not be considered when discovering test scenarios.
+func (s *Suite) ignored(fp string, isDir bool, ignores []ignorePattern) bool {
+	if filepath.Base(fp) == IgnoreFileName {
+		return true
+	}
+	for _, p := range ignores {
+		if p.matches(fp, isDir) {
+			return true
+		}
+	}
+	return s.matchesAny(s.exclude, fp)
+}
+
+// matchesAny returns true if the supplied absolute path, relative to the root
+// suite's directory, or its base name matches any of the supplied glob
+// patterns.
+func (s *Suite) matchesAny(globs []string, fp string) bool {
+	rel, err := filepath.Rel(s.root, fp)
+	if err != nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	return lo.SomeBy(globs, func(glob string) bool {
+		return matchesGlob(glob, rel)
+	})
+}
+
+// child returns a new Suite for the supplied subdirectory path that inherits
+// the discovery options, concurrency, on-failure policy, defaults and fixtures
+// of the Suite.
+func (s *Suite) child(dirPath string) *Suite {
+	return &Suite{
+		Path:           dirPath,
+		Concurrency:    s.Concurrency,
+		OnFailure:      s.OnFailure,
+		Defaults:       s.Defaults,
+		Fixtures:       s.Fixtures,
+		root:           s.root,
+		discovery:      s.discovery,
+		include:        s.include,
+		exclude:        s.exclude,
+		collectErrors:  s.collectErrors,
+		expandOpts:     s.expandOpts,
+		validateSchema: s.validateSchema,
+	}
 }
 
 // FromScenario encapsulates a given scenario in a fresh suite and returns it.
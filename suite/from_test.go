@@ -5,14 +5,99 @@
 package suite_test
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/gdt-dev/core/api"
+	"github.com/gdt-dev/core/parse"
 	_ "github.com/gdt-dev/core/plugin/exec"
+	"github.com/gdt-dev/core/scenario"
 	"github.com/gdt-dev/core/suite"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// parseErrorsDir contains scenario files that fail to parse.
+var parseErrorsDir = map[string]string{
+	"unknown-spec-1.yaml": `name: unknown-spec-1
+description: a scenario containing a test spec no plugin can parse
+tests:
+  - unknown: spec
+`,
+	"unknown-spec-2.yaml": `name: unknown-spec-2
+description: a scenario containing a test spec no plugin can parse
+tests:
+  - unknown: spec
+`,
+}
+
+// badManifestDir contains a suite manifest that fails to parse.
+var badManifestDir = map[string]string{
+	"suite.yaml": `name: bad-manifest
+bogus: field
+`,
+	"ls.yaml": `name: ls
+tests:
+  - exec: ls
+`,
+}
+
+// manifestDir contains suite manifests requiring the "counter" and "other"
+// fixtures.
+var manifestDir = map[string]string{
+	"suite.yaml": `name: manifest
+description: a test suite described by a manifest
+on-failure: continue
+fixtures:
+  - counter
+defaults:
+  timeout: 2s
+  retry:
+    attempts: 3
+    interval: 1s
+`,
+	"inherits.yaml": `name: inherits
+description: a scenario that inherits all of its suite's defaults
+tests:
+  - exec: ls
+`,
+	"overrides.yaml": `name: overrides
+description: a scenario that overrides some of its suite's defaults
+on-failure: stop
+defaults:
+  retry:
+    attempts: 5
+tests:
+  - exec: ls
+`,
+	"nested/_suite.yaml": `name: nested-manifest
+fixtures:
+  - other
+defaults:
+  timeout: 3s
+`,
+	"nested/child.yaml": `name: child
+description: a scenario in a nested suite
+tests:
+  - exec: ls
+`,
+}
+
+// writeDir writes the supplied files, keyed by slash-separated relative path,
+// to a temporary directory and returns the directory's path. These fixtures
+// are not kept in testdata because they cannot be run by TestRunExecSuite.
+func writeDir(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, contents := range files {
+		fp := filepath.Join(dir, filepath.FromSlash(name))
+		require.Nil(t, os.MkdirAll(filepath.Dir(fp), 0o755))
+		require.Nil(t, os.WriteFile(fp, []byte(contents), 0o644))
+	}
+	return dir
+}
+
 func TestFromDirNoSuchDir(t *testing.T) {
 	require := require.New(t)
 
@@ -35,3 +120,232 @@ func TestFromDirExecSuite(t *testing.T) {
 	// should not appear in the collected Suite.Tests.
 	assert.Len(s.Scenarios, 2)
 }
+
+func TestFromDirSchemaValidation(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s, err := suite.FromDir(
+		"testdata",
+		suite.WithRecursive(true),
+		suite.WithSchemaValidation(true),
+	)
+	require.Nil(err)
+	require.NotNil(s)
+	assert.NotEmpty(s.Suites)
+}
+
+func TestFromDirFlat(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s, err := suite.FromDir("testdata/recursive")
+	require.Nil(err)
+	require.NotNil(s)
+
+	// By default, the scenarios in subdirectories are added to the suite's
+	// own scenarios.
+	names := []string{}
+	for _, sc := range s.Scenarios {
+		names = append(names, sc.Name)
+	}
+	assert.ElementsMatch([]string{"top", "mid", "bottom"}, names)
+	assert.Empty(s.Suites)
+}
+
+func TestFromDirNotRecursive(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s, err := suite.FromDir("testdata/recursive", suite.WithRecursive(false))
+	require.Nil(err)
+	require.NotNil(s)
+
+	require.Len(s.Scenarios, 1)
+	assert.Equal("top", s.Scenarios[0].Name)
+	assert.Empty(s.Suites)
+}
+
+func TestFromDirRecursive(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s, err := suite.FromDir("testdata/recursive", suite.WithRecursive(true))
+	require.Nil(err)
+	require.NotNil(s)
+
+	// NOTE: The top-ignored.yaml file and the ignored/ directory
+	// contain scenarios that fail to parse but are listed in the suite's
+	// .gdtignore file and therefore never considered.
+	require.Len(s.Scenarios, 1)
+	assert.Equal("top", s.Scenarios[0].Name)
+	require.Len(s.Suites, 1)
+
+	nested := s.Suites[0]
+	assert.Equal("nested", filepath.Base(nested.Path))
+	require.Len(nested.Scenarios, 1)
+	assert.Equal("mid", nested.Scenarios[0].Name)
+	require.Len(nested.Suites, 1)
+
+	deeper := nested.Suites[0]
+	assert.Equal("deeper", filepath.Base(deeper.Path))
+	require.Len(deeper.Scenarios, 1)
+	assert.Equal("bottom", deeper.Scenarios[0].Name)
+	assert.Empty(deeper.Suites)
+}
+
+func TestFromDirInclude(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s, err := suite.FromDir(
+		"testdata/recursive",
+		suite.WithRecursive(true),
+		suite.WithInclude("nested/deeper/*.yaml"),
+	)
+	require.Nil(err)
+	require.NotNil(s)
+
+	// Directories without any included scenarios are not returned as nested
+	// suites.
+	assert.Empty(s.Scenarios)
+	require.Len(s.Suites, 1)
+	nested := s.Suites[0]
+	assert.Empty(nested.Scenarios)
+	require.Len(nested.Suites, 1)
+	deeper := nested.Suites[0]
+	require.Len(deeper.Scenarios, 1)
+	assert.Equal("bottom", deeper.Scenarios[0].Name)
+}
+
+func TestFromDirExclude(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s, err := suite.FromDir(
+		"testdata/recursive",
+		suite.WithRecursive(true),
+		suite.WithExclude("deeper", "top.yaml"),
+	)
+	require.Nil(err)
+	require.NotNil(s)
+
+	assert.Empty(s.Scenarios)
+	require.Len(s.Suites, 1)
+	nested := s.Suites[0]
+	require.Len(nested.Scenarios, 1)
+	assert.Equal("mid", nested.Scenarios[0].Name)
+	assert.Empty(nested.Suites)
+}
+
+func TestFromDirStopsAtFirstError(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s, err := suite.FromDir(writeDir(t, parseErrorsDir))
+	require.NotNil(err)
+	require.Nil(s)
+
+	assert.Contains(err.Error(), "unknown-spec-1.yaml")
+	assert.NotContains(err.Error(), "unknown-spec-2.yaml")
+}
+
+func TestFromDirCollectErrors(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s, err := suite.FromDir(
+		writeDir(t, parseErrorsDir),
+		suite.WithCollectErrors(true),
+	)
+	require.NotNil(err)
+	require.Nil(s)
+
+	var perr *parse.Error
+	assert.True(errors.As(err, &perr))
+	assert.Contains(err.Error(), "unknown-spec-1.yaml")
+	assert.Contains(err.Error(), "unknown-spec-2.yaml")
+}
+
+func TestFromDirWarnings(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s, err := suite.FromDir("testdata/exec", suite.WithCollectErrors(true))
+	require.Nil(err)
+	require.NotNil(s)
+	assert.Len(s.Scenarios, 2)
+
+	require.Len(s.Warnings, 1)
+	assert.ErrorIs(s.Warnings[0], api.ErrNoTests)
+	assert.ErrorContains(s.Warnings[0], "not-a-scenario.yaml")
+}
+
+func TestFromDirManifest(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s, err := suite.FromDir(
+		writeDir(t, manifestDir),
+		suite.WithRecursive(true),
+	)
+	require.Nil(err)
+	require.NotNil(s)
+
+	assert.Equal("manifest", s.Name)
+	assert.Equal("a test suite described by a manifest", s.Description)
+	assert.Equal([]string{"counter"}, s.Fixtures)
+	assert.Equal(api.OnFailureContinue, s.OnFailure)
+
+	// NOTE: The suite.yaml manifest is not a test scenario.
+	require.Len(s.Scenarios, 2)
+
+	inherits := s.Scenarios[0]
+	assert.Equal("inherits", inherits.Name)
+	assert.Equal([]string{"counter"}, inherits.Fixtures)
+	assert.Equal(api.OnFailureContinue, inherits.OnFailure)
+	defaults := inherits.Defaults[scenario.DefaultsKey].(*scenario.Defaults)
+	require.NotNil(defaults.Timeout)
+	assert.Equal("2s", defaults.Timeout.After)
+	require.NotNil(defaults.Retry)
+	assert.Equal(3, *defaults.Retry.Attempts)
+	assert.Equal("1s", defaults.Retry.Interval)
+
+	overrides := s.Scenarios[1]
+	assert.Equal("overrides", overrides.Name)
+	assert.Equal(api.OnFailureStop, overrides.OnFailure)
+	defaults = overrides.Defaults[scenario.DefaultsKey].(*scenario.Defaults)
+	require.NotNil(defaults.Timeout)
+	assert.Equal("2s", defaults.Timeout.After)
+	require.NotNil(defaults.Retry)
+	assert.Equal(5, *defaults.Retry.Attempts)
+	assert.Equal("1s", defaults.Retry.Interval)
+
+	require.Len(s.Suites, 1)
+	nested := s.Suites[0]
+	assert.Equal("nested-manifest", nested.Name)
+	assert.Equal([]string{"counter", "other"}, nested.Fixtures)
+	require.Len(nested.Scenarios, 1)
+
+	child := nested.Scenarios[0]
+	assert.Equal([]string{"counter", "other"}, child.Fixtures)
+	assert.Equal(api.OnFailureContinue, child.OnFailure)
+	defaults = child.Defaults[scenario.DefaultsKey].(*scenario.Defaults)
+	require.NotNil(defaults.Timeout)
+	assert.Equal("3s", defaults.Timeout.After)
+	require.NotNil(defaults.Retry)
+	assert.Equal(3, *defaults.Retry.Attempts)
+}
+
+func TestFromDirManifestUnknownField(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s, err := suite.FromDir(writeDir(t, badManifestDir))
+	require.NotNil(err)
+	require.Nil(s)
+
+	assert.ErrorIs(err, parse.ErrParseUnknownField)
+	assert.Contains(err.Error(), "suite.yaml")
+	assert.Contains(err.Error(), "bogus")
+}
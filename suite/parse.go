@@ -0,0 +1,212 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package suite
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gdt-dev/core/api"
+	"github.com/gdt-dev/core/parse"
+	"github.com/gdt-dev/core/plugin"
+	"github.com/gdt-dev/core/scenario"
+)
+
+var (
+	// manifestFileNames are the names of the files that, when present in a
+	// suite directory, describe the test suite itself instead of a test
+	// scenario.
+	manifestFileNames = []string{"suite.yaml", "_suite.yaml"}
+)
+
+// UnmarshalYAML is a custom unmarshaler that parses a test suite manifest
+// (a `suite.yaml` or `_suite.yaml` file) and validates the suite's defaults
+// against the known plugins.
+func (s *Suite) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return parse.ExpectedMapAt(node)
+	}
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		switch key {
+		case "name":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			s.Name = valNode.Value
+		case "description":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			s.Description = valNode.Value
+		case "concurrency":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			v, err := strconv.Atoi(valNode.Value)
+			if err != nil {
+				return parse.ExpectedIntAt(valNode)
+			}
+			s.Concurrency = v
+		case "on-failure":
+			var o api.OnFailure
+			if err := valNode.Decode(&o); err != nil {
+				return err
+			}
+			s.OnFailure = o
+		case "fixtures":
+			if valNode.Kind != yaml.SequenceNode {
+				return parse.ExpectedSequenceAt(valNode)
+			}
+			var fixtures []string
+			if err := valNode.Decode(&fixtures); err != nil {
+				return parse.ExpectedSequenceAt(valNode)
+			}
+			s.Fixtures = fixtures
+		case "defaults":
+			if valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedMapAt(valNode)
+			}
+			// The suite's defaults are handed to each contained scenario in
+			// raw form, but we ask the plugins and the scenario to interpret
+			// them here so that invalid defaults are reported against the
+			// manifest instead of against every scenario.
+			for _, p := range plugin.Registered() {
+				if err := valNode.Decode(p.Defaults()); err != nil {
+					return err
+				}
+			}
+			var scenDefaults scenario.Defaults
+			if err := valNode.Decode(&scenDefaults); err != nil {
+				return err
+			}
+			var defaults map[string]interface{}
+			if err := valNode.Decode(&defaults); err != nil {
+				return parse.ExpectedMapAt(valNode)
+			}
+			s.Defaults = defaults
+		case "vars":
+			var vars api.Vars
+			if err := valNode.Decode(&vars); err != nil {
+				return err
+			}
+			s.Vars = vars
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
+		}
+	}
+	return nil
+}
+
+// manifestFromDir parses the test suite manifest in the supplied directory,
+// returning nil if the directory has no manifest. References to environment
+// variables are expanded using the supplied options. The path to the manifest
+// file is returned alongside any parse error.
+func manifestFromDir(
+	dir string,
+	opts ...parse.ExpandOption,
+) (*Suite, string, error) {
+	for _, fname := range manifestFileNames {
+		fp := filepath.Join(dir, fname)
+		contents, err := os.ReadFile(fp)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, fp, err
+		}
+		m := &Suite{}
+		if err := parse.UnmarshalExpanded(contents, m, opts...); err != nil {
+			if ep, ok := err.(*parse.Error); ok {
+				ep.Path = fp
+				ep.SetContents()
+				return nil, fp, ep
+			}
+			return nil, fp, fmt.Errorf("error parsing %q: %w", fp, err)
+		}
+		return m, fp, nil
+	}
+	return nil, "", nil
+}
+
+// applyManifest applies the name, description, concurrency, on-failure
+// policy, defaults, fixtures and variables declared in a test suite manifest
+// to the Suite. Values in the manifest override any inherited values, except
+// for defaults, which are merged with (and override) inherited defaults, and
+// fixtures, which are added to the inherited fixtures. Variables are never
+// inherited by nested suites at load time; they are seeded into the context
+// when the suite is run.
+func (s *Suite) applyManifest(m *Suite) {
+	if m.Name != "" {
+		s.Name = m.Name
+	}
+	if m.Description != "" {
+		s.Description = m.Description
+	}
+	if m.Concurrency != 0 {
+		s.Concurrency = m.Concurrency
+	}
+	if m.OnFailure != "" {
+		s.OnFailure = m.OnFailure
+	}
+	s.Defaults = mergeDefaults(s.Defaults, m.Defaults)
+	s.Fixtures = mergeFixtures(s.Fixtures, m.Fixtures)
+	s.Vars = m.Vars
+}
+
+// mergeDefaults returns a new map of raw default configuration values
+// containing the base values overlaid with the override values. Nested maps
+// are merged recursively and, for any other kind of value, the override's
+// value wins.
+func mergeDefaults(
+	base map[string]interface{},
+	override map[string]interface{},
+) map[string]interface{} {
+	if len(base) == 0 && len(override) == 0 {
+		return nil
+	}
+	merged := make(map[string]interface{}, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		baseMap, baseOK := merged[k].(map[string]interface{})
+		overMap, overOK := v.(map[string]interface{})
+		if baseOK && overOK {
+			merged[k] = mergeDefaults(baseMap, overMap)
+			continue
+		}
+		merged[k] = v
+	}
+	return merged
+}
+
+// mergeFixtures returns the base fixture names followed by any of the
+// additional fixture names not already in the base, preserving order.
+func mergeFixtures(base []string, additional []string) []string {
+	merged := slices.Clone(base)
+	for _, fname := range additional {
+		if !slices.ContainsFunc(merged, func(f string) bool {
+			return strings.EqualFold(f, fname)
+		}) {
+			merged = append(merged, fname)
+		}
+	}
+	return merged
+}
@@ -6,10 +6,122 @@ package suite
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gdt-dev/core/api"
+	gdtcontext "github.com/gdt-dev/core/context"
+	"github.com/gdt-dev/core/run"
 )
 
-// Run executes the tests in the test suite
+// Run executes the tests in the test suite followed by the tests in any nested
+// test suites
+//
+// The suite's fixtures are started once, before any of its tests, and stopped
+// after all of them have completed. Scenarios and nested suites requiring
+// those fixtures do not start them again.
+//
+// The suite's variables are seeded into the context's run data before any of
+// its tests, so that they are visible to its scenarios and nested suites.
+// Likewise, the suite's on-failure policy applies to its scenarios and nested
+// suites that do not declare their own.
 func (s *Suite) Run(ctx context.Context, subject any) error {
+	if s.OnFailure != "" {
+		// Scenarios, including those in nested suites, that don't declare
+		// their own policy use the suite's.
+		ctx = gdtcontext.SetOnFailure(ctx, s.OnFailure)
+	}
+	if len(s.Vars) > 0 {
+		vars, err := s.Vars.Resolve(s.Path)
+		if err != nil {
+			s.storeError(subject, err)
+			return err
+		}
+		ctx = gdtcontext.SetRun(ctx, vars)
+	}
+	if len(s.Fixtures) > 0 {
+		fixtures := gdtcontext.Fixtures(ctx)
+		started := []string{}
+		for _, fname := range s.Fixtures {
+			if gdtcontext.FixtureStarted(ctx, fname) {
+				continue
+			}
+			lookup := strings.ToLower(fname)
+			fix, found := fixtures[lookup]
+			if !found {
+				err := api.RequiredFixtureMissing(fname)
+				s.storeError(subject, err)
+				return err
+			}
+			if err := fix.Start(ctx); err != nil {
+				err = api.FixtureStartFailed(fname, err)
+				s.storeError(subject, err)
+				return err
+			}
+			defer fix.Stop(ctx)
+			started = append(started, fname)
+		}
+		ctx = gdtcontext.SetFixturesStarted(ctx, started...)
+	}
+	if err := s.runScenarios(ctx, subject); err != nil {
+		return err
+	}
+	for _, child := range s.Suites {
+		if err := child.runNested(ctx, subject); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storeError records a runtime error that prevented the suite's tests from
+// running against every scenario in the suite and its nested suites when
+// using the `gdt` CLI tool as the underlying test runner. Each scenario is
+// reported as errored but the Run's Summary only counts the error once.
+func (s *Suite) storeError(subject any, err error) {
+	r, ok := subject.(*run.Run)
+	if !ok {
+		return
+	}
+	for _, sc := range s.Scenarios {
+		r.StoreError(sc.Path, err)
+	}
+	for _, child := range s.Suites {
+		child.storeError(r, err)
+	}
+}
+
+// runNested executes a nested test suite. When using the `go test` tool as the
+// underlying test runner, the nested suite is run in a subtest named for its
+// directory so that test names mirror the directory tree.
+func (s *Suite) runNested(ctx context.Context, subject any) error {
+	t, ok := subject.(*testing.T)
+	if !ok {
+		return s.Run(ctx, subject)
+	}
+	var err error
+	t.Run(filepath.Base(s.Path), func(t *testing.T) {
+		err = s.Run(ctx, t)
+	})
+	return err
+}
+
+// runScenarios executes the suite's own test scenarios
+func (s *Suite) runScenarios(ctx context.Context, subject any) error {
+	if s.Concurrency > 1 {
+		switch subject := subject.(type) {
+		case *testing.T:
+			return s.runParallelGo(ctx, subject)
+		case *run.Run:
+			return s.runParallelExternal(ctx, subject)
+		default:
+			return fmt.Errorf("unknown run type %T", subject)
+		}
+	}
 	for _, sc := range s.Scenarios {
 		if err := sc.Run(ctx, subject); err != nil {
 			return err
@@ -17,3 +129,49 @@ func (s *Suite) Run(ctx context.Context, subject any) error {
 	}
 	return nil
 }
+
+// runParallelGo executes the suite's scenarios in parallel using the `go test`
+// tool as the underlying test runner. At most Suite.Concurrency scenarios are
+// executing at any one time. Each scenario is run in a subtest named for the
+// scenario, exactly as when the scenarios are run serially, so that test
+// names don't depend on the suite's concurrency.
+func (s *Suite) runParallelGo(ctx context.Context, t *testing.T) error {
+	sem := make(chan struct{}, s.Concurrency)
+	errs := make([]error, len(s.Scenarios))
+	var wg sync.WaitGroup
+	for idx, sc := range s.Scenarios {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			// t.Run may be called from multiple goroutines at once as long
+			// as all of the calls return before the test function does.
+			t.Run(sc.Title(), func(t *testing.T) {
+				errs[idx] = sc.RunInSubtest(ctx, t)
+			})
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// runParallelExternal executes the suite's scenarios in parallel using the
+// `gdt` CLI tool as the underlying test runner. At most Suite.Concurrency
+// scenarios are executing at any one time.
+func (s *Suite) runParallelExternal(ctx context.Context, r *run.Run) error {
+	sem := make(chan struct{}, s.Concurrency)
+	errs := make([]error, len(s.Scenarios))
+	var wg sync.WaitGroup
+	for idx, sc := range s.Scenarios {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			errs[idx] = sc.Run(ctx, r)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -6,8 +6,15 @@ package suite_test
 
 import (
 	"context"
+	"os"
+	"os/exec"
 	"testing"
 
+	"github.com/gdt-dev/core/api"
+	gdtcontext "github.com/gdt-dev/core/context"
+	"github.com/gdt-dev/core/fixture"
+	"github.com/gdt-dev/core/run"
+	"github.com/gdt-dev/core/scenario"
 	"github.com/gdt-dev/core/suite"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -25,3 +32,164 @@ func TestRunExecSuite(t *testing.T) {
 	err = s.Run(ctx, t)
 	assert.Nil(err)
 }
+
+func TestRunExecSuiteParallel(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	s, err := suite.FromDir("testdata/exec", suite.WithConcurrency(2))
+	require.Nil(err)
+	require.NotNil(s)
+	require.Len(s.Scenarios, 2)
+
+	ctx := context.TODO()
+	err = s.Run(ctx, t)
+	assert.Nil(err)
+}
+
+func TestRunExecSuiteParallelTestNames(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	target := os.Args[0]
+	args := []string{
+		"-test.v",
+		"-test.run=^TestRunExecSuiteParallel$",
+	}
+	outerr, err := exec.Command(target, args...).CombinedOutput()
+	require.Nil(err)
+
+	// The scenarios' subtests are named exactly as when the suite's
+	// scenarios are run serially.
+	out := string(outerr)
+	assert.Contains(out, "--- PASS: TestRunExecSuiteParallel/ls (")
+	assert.Contains(out, "--- PASS: TestRunExecSuiteParallel/echo-cat (")
+	assert.NotContains(out, "TestRunExecSuiteParallel/ls/")
+	assert.NotContains(out, "TestRunExecSuiteParallel/echo-cat/")
+}
+
+func TestRunExecSuiteParallelExternal(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	s, err := suite.FromDir("testdata/exec", suite.WithConcurrency(2))
+	require.Nil(err)
+	require.NotNil(s)
+
+	r := run.New()
+	ctx := context.TODO()
+	err = s.Run(ctx, r)
+	assert.Nil(err)
+	assert.True(r.OK())
+	assert.Len(r.ScenarioPaths(), 2)
+}
+
+func TestRunSuiteFixturesStartedOnce(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	s, err := suite.FromDir(
+		writeDir(t, manifestDir),
+		suite.WithRecursive(true),
+	)
+	require.Nil(err)
+	require.NotNil(s)
+
+	starts := map[string]int{}
+	stops := map[string]int{}
+	ctx := context.TODO()
+	for _, name := range []string{"counter", "other"} {
+		ctx = gdtcontext.RegisterFixture(ctx, name, fixture.New(
+			fixture.WithStarter(func(_ context.Context) error {
+				starts[name]++
+				return nil
+			}),
+			fixture.WithStopper(func(_ context.Context) {
+				stops[name]++
+			}),
+		))
+	}
+
+	err = s.Run(ctx, t)
+	assert.Nil(err)
+	assert.Equal(map[string]int{"counter": 1, "other": 1}, starts)
+	assert.Equal(map[string]int{"counter": 1, "other": 1}, stops)
+}
+
+func TestRunSuiteVars(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	s, err := suite.FromDir("testdata/vars", suite.WithRecursive(true))
+	require.Nil(err)
+	require.NotNil(s)
+	require.Len(s.Scenarios, 1)
+	require.Len(s.Suites, 1)
+	assert.Len(s.Vars, 3)
+	assert.Empty(s.Suites[0].Vars)
+
+	ctx := context.TODO()
+	err = s.Run(ctx, t)
+	assert.Nil(err)
+}
+
+func TestRunSuiteVarsResolveError(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	s, err := suite.FromDir("testdata/exec")
+	require.Nil(err)
+	require.NotNil(s)
+	s.Vars = api.Vars{{Name: "HOST", Env: "GDT_TEST_VARS_UNSET"}}
+
+	r := run.New()
+	ctx := context.TODO()
+	err = s.Run(ctx, r)
+	assert.ErrorIs(err, api.ErrVarResolve)
+	assert.ErrorContains(err, "GDT_TEST_VARS_UNSET")
+	assert.False(r.OK())
+
+	// The suite-level error is reported against each of the suite's
+	// scenarios but only counted once.
+	sum := r.Summary()
+	assert.Len(sum.Errors, 1)
+	require.Len(sum.Scenarios, 2)
+	for _, ss := range sum.Scenarios {
+		assert.Equal(run.StatusErrored, ss.Status)
+	}
+}
+
+func TestRunSuiteOnFailure(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	contents := []byte(`name: no-policy
+tests:
+  - exec: "false"
+  - exec: "true"
+`)
+	cases := []struct {
+		onFailure api.OnFailure
+		skipped   bool
+	}{
+		{onFailure: "", skipped: true},
+		{onFailure: api.OnFailureContinue, skipped: false},
+	}
+	for _, c := range cases {
+		sc, err := scenario.FromBytes(contents, scenario.WithPath("no-policy"))
+		require.Nil(err)
+		require.Empty(sc.OnFailure)
+
+		s := suite.New(suite.WithOnFailure(c.onFailure))
+		s.Append(sc)
+
+		r := run.New()
+		err = s.Run(context.TODO(), r)
+		require.Nil(err)
+
+		results := r.ScenarioResults("no-policy")
+		require.Len(results, 2)
+		assert.False(results[0].OK())
+		assert.Equal(c.skipped, results[1].Skipped())
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"os"
 	"strings"
 
+	"github.com/gdt-dev/core/api"
+	"github.com/gdt-dev/core/parse"
 	"github.com/gdt-dev/core/scenario"
 )
 
@@ -30,8 +32,58 @@ type Suite struct {
 	// Fixtures specifies an ordered list of fixtures the test suite's test
 	// cases depend on.
 	Fixtures []string `yaml:"fixtures,omitempty"`
+	// Vars contains variables that are resolved when the suite is run and
+	// seeded into the context's run data before any of its scenarios, so
+	// that the test specs in the suite's scenarios (and in its nested
+	// suites) may refer to them. Relative file paths are resolved against the
+	// suite's directory.
+	Vars api.Vars `yaml:"vars,omitempty"`
+	// Concurrency is the maximum number of the suite's scenarios that may be
+	// run at the same time. A value of 0 or 1 (the default) runs the
+	// scenarios serially, in order, stopping at the first runtime error.
+	//
+	// When greater than 1, scenarios are run in parallel and the runtime
+	// errors from all scenarios are collected and returned together. Each
+	// scenario must therefore be independent of the others.
+	Concurrency int `yaml:"concurrency,omitempty"`
+	// OnFailure is the default policy for whether a scenario's remaining test
+	// specs are executed after a test spec fails. Scenarios in the suite (and
+	// its nested suites) that do not declare their own `on-failure` policy
+	// use this one.
+	OnFailure api.OnFailure `yaml:"on-failure,omitempty"`
 	// Scenarios is a collection of test scenarios in this test suite
 	Scenarios []*scenario.Scenario `yaml:"-"`
+	// Suites is a collection of nested test suites, one for each
+	// subdirectory containing test scenarios when the suite was discovered
+	// recursively. Nested suites are run after the suite's own scenarios.
+	Suites []*Suite `yaml:"-"`
+	// Warnings contains the problems found when the suite was discovered
+	// that did not prevent its scenarios from being loaded, e.g. YAML files
+	// in the suite's directory or its subdirectories that don't contain any
+	// tests.
+	Warnings []error `yaml:"-"`
+
+	// root is the absolute path to the directory that discovery started
+	// from. Include and exclude patterns are matched relative to it.
+	root string
+	// discovery is how subdirectories of the test suite directory are
+	// handled when discovering test scenarios.
+	discovery discovery
+	// include contains glob patterns of scenario files to consider. When
+	// empty, all YAML files are considered.
+	include []string
+	// exclude contains glob patterns of scenario files and directories to
+	// skip.
+	exclude []string
+	// collectErrors is true when all scenario files should be parsed and all
+	// parse errors returned instead of stopping at the first one.
+	collectErrors bool
+	// expandOpts configures the expansion of environment variable references
+	// in the suite's manifests and scenario files.
+	expandOpts []parse.ExpandOption
+	// validateSchema is true when scenario files are validated against the
+	// JSON Schema of the registered plugins before being parsed.
+	validateSchema bool
 }
 
 // Title returns the nem of the Suite or, if missing, the short path to the
@@ -89,6 +141,101 @@ func WithFixtures(fixtures []string) SuiteModifier {
 	}
 }
 
+// WithVars sets a test suite's Vars attribute
+func WithVars(vars api.Vars) SuiteModifier {
+	return func(s *Suite) {
+		s.Vars = vars
+	}
+}
+
+// WithConcurrency sets a test suite's Concurrency attribute
+func WithConcurrency(concurrency int) SuiteModifier {
+	return func(s *Suite) {
+		s.Concurrency = concurrency
+	}
+}
+
+// WithOnFailure sets a test suite's OnFailure attribute. The policy applies,
+// when the suite is run, to any of the suite's scenarios that do not declare
+// their own, however the scenarios were added to the suite.
+func WithOnFailure(onFailure api.OnFailure) SuiteModifier {
+	return func(s *Suite) {
+		s.OnFailure = onFailure
+	}
+}
+
+// discovery is how subdirectories of a test suite directory are handled when
+// discovering test scenarios.
+type discovery int
+
+const (
+	// discoverFlat adds the scenarios found in all subdirectories to the
+	// suite's own scenarios. This is the default.
+	discoverFlat discovery = iota
+	// discoverNested creates a nested suite for each subdirectory.
+	discoverNested
+	// discoverTopLevel ignores subdirectories.
+	discoverTopLevel
+)
+
+// WithRecursive sets whether subdirectories of the test suite directory are
+// discovered as nested test suites (true) or ignored (false). Without this
+// modifier, the scenarios in all subdirectories are added to the suite's own
+// scenarios.
+func WithRecursive(recursive bool) SuiteModifier {
+	return func(s *Suite) {
+		if recursive {
+			s.discovery = discoverNested
+		} else {
+			s.discovery = discoverTopLevel
+		}
+	}
+}
+
+// WithInclude sets the glob patterns of scenario files to consider when
+// discovering test scenarios. Patterns are matched against the file's path
+// relative to the test suite directory and against the file's base name.
+func WithInclude(patterns ...string) SuiteModifier {
+	return func(s *Suite) {
+		s.include = append(s.include, patterns...)
+	}
+}
+
+// WithExclude sets the glob patterns of scenario files and directories to
+// skip when discovering test scenarios. Patterns are matched against the
+// path relative to the test suite directory and against the base name.
+func WithExclude(patterns ...string) SuiteModifier {
+	return func(s *Suite) {
+		s.exclude = append(s.exclude, patterns...)
+	}
+}
+
+// WithCollectErrors sets whether all scenario files are parsed and every
+// parse error is returned instead of stopping at the first failed file.
+func WithCollectErrors(collect bool) SuiteModifier {
+	return func(s *Suite) {
+		s.collectErrors = collect
+	}
+}
+
+// WithEnvExpansion sets the options used when expanding references to
+// environment variables in the test suite's manifests and scenario files,
+// e.g. `parse.WithStrictEnv(true)`.
+func WithEnvExpansion(opts ...parse.ExpandOption) SuiteModifier {
+	return func(s *Suite) {
+		s.expandOpts = append(s.expandOpts, opts...)
+	}
+}
+
+// WithSchemaValidation sets whether the test suite's scenario files are
+// validated against the JSON Schema of the registered plugins (see
+// scenario.Schema) before being parsed.
+func WithSchemaValidation(validate bool) SuiteModifier {
+	return func(s *Suite) {
+		s.validateSchema = validate
+	}
+}
+
 // New returns a new Suite
 func New(mods ...SuiteModifier) *Suite {
 	s := &Suite{}
@@ -61,9 +61,15 @@ func (u *TestUnit) Name() string {
 	return u.name
 }
 
-// Elapsed returns the duration the test took to execute.
+// Elapsed returns the duration the test took to execute. If the test unit has
+// not yet finished, returns the duration since the test unit was started.
 func (u *TestUnit) Elapsed() time.Duration {
-	return u.elapsed
+	u.RLock()
+	defer u.RUnlock()
+	if u.done {
+		return u.elapsed
+	}
+	return u.elapsed + time.Since(u.started)
 }
 
 // Detail returns the saved log entries.
@@ -179,8 +185,8 @@ func (u *TestUnit) Skipf(format string, args ...any) {
 // SkipNow marks the test unit as having been skipped and stops its execution.
 func (u *TestUnit) SkipNow() {
 	u.Lock()
-	defer u.RUnlock()
 	u.skipped = true
+	u.Unlock()
 	u.finish()
 }
 
@@ -0,0 +1,379 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+// Package variable implements the substitution of variable references in test
+// spec contents with the values of variables saved by prior test specs.
+//
+// A reference takes one of the following forms:
+//
+//	$name             the value of the variable "name"
+//	${name}           the value of the variable "name"
+//	${name:-default}  the value of "name" or "default" if "name" is
+//	                  undefined or empty or the selected field does not
+//	                  exist
+//	${name.field[0]}  a field or element of the structured value of "name"
+//	$${name}          the literal string "${name}"
+//	$$name            the literal string "$name"
+//
+// Variable names in the short `$name` form contain only letters, digits and
+// underscores, and the longest such name is always used, so `$ID2` never
+// refers to the variable "ID". Names containing other characters, e.g. dashes,
+// must be referenced using the `${name}` form. References to undefined
+// variables without a default are left untouched, which allows shell variable
+// references to pass through to executed commands.
+package variable
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrFieldNotFound is returned when a reference selects a field or element
+// that does not exist in the structured value of a variable.
+var ErrFieldNotFound = errors.New("variable field not found")
+
+// FieldNotFound returns an ErrFieldNotFound for the supplied reference.
+func FieldNotFound(ref string, reason string) error {
+	return fmt.Errorf("%w: %s: %s", ErrFieldNotFound, ref, reason)
+}
+
+// Lookup returns the value of the named variable and true, or nil and false if
+// the variable is not defined.
+type Lookup func(name string) (any, bool)
+
+// MapLookup returns a Lookup for the variables in the supplied map.
+func MapLookup(vars map[string]any) Lookup {
+	return func(name string) (any, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+}
+
+// Expand returns the supplied subject with all references to defined
+// variables replaced with the formatted values of those variables. If a
+// reference selects a field that does not exist, an ErrFieldNotFound is
+// returned along with the subject in which that reference was left untouched.
+func Expand(subject string, lookup Lookup) (string, error) {
+	if !strings.Contains(subject, "$") {
+		return subject, nil
+	}
+	var b strings.Builder
+	var firstErr error
+	for i := 0; i < len(subject); {
+		ref, n := scan(subject[i:])
+		if n == 0 {
+			b.WriteByte(subject[i])
+			i++
+			continue
+		}
+		raw := subject[i : i+n]
+		i += n
+		if ref == nil {
+			// An escaped reference
+			b.WriteString(raw[1:])
+			continue
+		}
+		v, found, err := ref.resolve(lookup)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			b.WriteString(raw)
+			continue
+		}
+		if !found {
+			b.WriteString(raw)
+			continue
+		}
+		b.WriteString(Format(v))
+	}
+	return b.String(), firstErr
+}
+
+// Value returns the value of the variable referenced by the supplied subject
+// if the subject consists of exactly one reference, preserving the type of
+// the variable's value. Otherwise, Value returns the expanded subject string.
+func Value(subject string, lookup Lookup) (any, error) {
+	ref, n := scan(subject)
+	if ref != nil && n == len(subject) {
+		v, found, err := ref.resolve(lookup)
+		if err != nil {
+			return subject, err
+		}
+		if found {
+			return v, nil
+		}
+		return subject, nil
+	}
+	return Expand(subject, lookup)
+}
+
+// Format returns the string representation of a variable's value. Strings are
+// returned as-is, numbers and booleans are formatted without loss of
+// precision and structured values are encoded as JSON.
+func Format(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case fmt.Stringer:
+		return v.String()
+	case error:
+		return v.Error()
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 64)
+	case reflect.String:
+		return rv.String()
+	}
+	if b, err := json.Marshal(v); err == nil {
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
+
+// reference is a parsed reference to a variable.
+type reference struct {
+	// text is the text of the reference, without the enclosing `${` and `}`
+	text string
+	// path contains the variable name and any field or element selectors
+	path []segment
+	// def is the default value, if any
+	def *string
+}
+
+// segment is a single step in a reference's path: either a map key or
+// struct field name, or a slice or array index.
+type segment struct {
+	key   string
+	index int
+	isIdx bool
+}
+
+// scan examines the start of the supplied string for a reference, returning
+// the parsed reference and the number of bytes it spans. A nil reference with
+// a non-zero length indicates an escaped reference. A zero length indicates
+// no reference starts the string.
+func scan(s string) (*reference, int) {
+	if len(s) < 2 || s[0] != '$' {
+		return nil, 0
+	}
+	switch {
+	case s[1] == '$':
+		// `$${name}` and `$$name` are escapes. Any other `$$` is left alone
+		// so that, e.g., the shell's PID variable passes through.
+		if len(s) > 2 && (s[2] == '{' || isNameStart(s[2])) {
+			if s[2] == '{' {
+				if end := strings.IndexByte(s[2:], '}'); end >= 0 {
+					return nil, end + 3
+				}
+				return nil, 0
+			}
+			return nil, 2 + nameLen(s[2:])
+		}
+		return nil, 0
+	case s[1] == '{':
+		end := strings.IndexByte(s, '}')
+		if end < 0 {
+			return nil, 0
+		}
+		ref, ok := parseReference(s[2:end])
+		if !ok {
+			return nil, 0
+		}
+		return ref, end + 1
+	case isNameStart(s[1]):
+		n := nameLen(s[1:])
+		name := s[1 : 1+n]
+		return &reference{
+			text: name,
+			path: []segment{{key: name}},
+		}, n + 1
+	}
+	return nil, 0
+}
+
+// parseReference parses the contents of a `${...}` reference.
+func parseReference(text string) (*reference, bool) {
+	ref := &reference{text: text}
+	if idx := strings.Index(text, ":-"); idx >= 0 {
+		def := text[idx+2:]
+		ref.def = &def
+		text = text[:idx]
+	}
+	if text == "" || !isNameStart(text[0]) {
+		return nil, false
+	}
+	for len(text) > 0 {
+		switch {
+		case text[0] == '.':
+			text = text[1:]
+			if text == "" {
+				return nil, false
+			}
+		case text[0] == '[':
+			end := strings.IndexByte(text, ']')
+			if end < 0 {
+				return nil, false
+			}
+			idx, err := strconv.Atoi(text[1:end])
+			if err != nil {
+				return nil, false
+			}
+			ref.path = append(ref.path, segment{index: idx, isIdx: true})
+			text = text[end+1:]
+			continue
+		}
+		n := keyLen(text)
+		if n == 0 {
+			return nil, false
+		}
+		ref.path = append(ref.path, segment{key: text[:n]})
+		text = text[n:]
+	}
+	return ref, true
+}
+
+// resolve returns the value of the reference and whether the referenced
+// variable is defined.
+func (r *reference) resolve(lookup Lookup) (any, bool, error) {
+	v, rest, found := r.lookupVar(lookup)
+	if !found {
+		if r.def != nil {
+			return *r.def, true, nil
+		}
+		return nil, false, nil
+	}
+	for _, seg := range rest {
+		var err error
+		v, err = selectSegment(v, seg)
+		if err != nil {
+			if r.def != nil {
+				return *r.def, true, nil
+			}
+			return nil, false, FieldNotFound(r.text, err.Error())
+		}
+	}
+	if r.def != nil && Format(v) == "" {
+		return *r.def, true, nil
+	}
+	return v, true, nil
+}
+
+// lookupVar returns the value of the variable named by the longest prefix of
+// the reference's path for which a variable is defined, along with the
+// remaining path segments. This allows variable names to contain dots.
+func (r *reference) lookupVar(lookup Lookup) (any, []segment, bool) {
+	keys := 0
+	for _, seg := range r.path {
+		if seg.isIdx {
+			break
+		}
+		keys++
+	}
+	for n := keys; n > 0; n-- {
+		names := make([]string, n)
+		for x := 0; x < n; x++ {
+			names[x] = r.path[x].key
+		}
+		if v, ok := lookup(strings.Join(names, ".")); ok {
+			return v, r.path[n:], true
+		}
+	}
+	return nil, nil, false
+}
+
+// selectSegment returns the field or element of the supplied value selected
+// by the supplied path segment.
+func selectSegment(v any, seg segment) (any, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, errors.New("nil value")
+		}
+		rv = rv.Elem()
+	}
+	if seg.isIdx {
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Array:
+			if seg.index < 0 || seg.index >= rv.Len() {
+				return nil, fmt.Errorf("index %d out of range", seg.index)
+			}
+			return rv.Index(seg.index).Interface(), nil
+		default:
+			return nil, fmt.Errorf("cannot index %s", rv.Kind())
+		}
+	}
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("cannot select %q from %s", seg.key, rv.Type())
+		}
+		fv := rv.MapIndex(reflect.ValueOf(seg.key).Convert(rv.Type().Key()))
+		if !fv.IsValid() {
+			return nil, fmt.Errorf("no key %q", seg.key)
+		}
+		return fv.Interface(), nil
+	case reflect.Struct:
+		rt := rv.Type()
+		for x := 0; x < rt.NumField(); x++ {
+			f := rt.Field(x)
+			if !f.IsExported() {
+				continue
+			}
+			name := strings.Split(f.Tag.Get("json"), ",")[0]
+			if strings.EqualFold(f.Name, seg.key) || name == seg.key {
+				return rv.Field(x).Interface(), nil
+			}
+		}
+		return nil, fmt.Errorf("no field %q", seg.key)
+	default:
+		return nil, fmt.Errorf("cannot select %q from %s", seg.key, rv.Kind())
+	}
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9')
+}
+
+// nameLen returns the length of the variable name at the start of s.
+func nameLen(s string) int {
+	n := 0
+	for n < len(s) && isNameChar(s[n]) {
+		n++
+	}
+	return n
+}
+
+// keyLen returns the length of the map key or field name at the start of s
+// within a `${...}` reference, where names may also contain dashes.
+func keyLen(s string) int {
+	n := 0
+	for n < len(s) && (isNameChar(s[n]) || s[n] == '-') {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,138 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package variable_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gdt-dev/core/variable"
+)
+
+type container struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+}
+
+var vars = map[string]any{
+	"ID":      "abc",
+	"ID2":     "def",
+	"empty":   "",
+	"my-var":  "dash",
+	"int":     42,
+	"int8":    int8(-8),
+	"uint64":  uint64(18446744073709551615),
+	"float":   3.25,
+	"float32": float32(0.5),
+	"bool":    true,
+	"bytes":   []byte("raw"),
+	"list":    []any{"a", "b", "c"},
+	"pod": map[string]any{
+		"name": "web-0",
+		"spec": map[string]any{
+			"containers": []container{
+				{Name: "nginx", Image: "nginx:1.25"},
+			},
+		},
+	},
+	"exec.background.server": map[string]any{
+		"pid": 1234,
+	},
+}
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject string
+		exp     string
+	}{
+		{"no refs", "hello world", "hello world"},
+		{"short", "id=$ID", "id=abc"},
+		{"longest name", "$ID2 $ID", "def abc"},
+		{"braces", "${ID}2", "abc2"},
+		{"dashed braces", "${my-var}", "dash"},
+		{"dashed short", "$my-var", "$my-var"},
+		{"undefined short", "$HOME/bin", "$HOME/bin"},
+		{"undefined braces", "${HOME}/bin", "${HOME}/bin"},
+		{"default unset", "${missing:-x}", "x"},
+		{"default empty", "${empty:-x}", "x"},
+		{"default set", "${ID:-x}", "abc"},
+		{"default blank", "${missing:-}", ""},
+		{"escaped braces", "$${ID}", "${ID}"},
+		{"escaped short", "$$ID", "$ID"},
+		{"shell pid", "kill $$", "kill $$"},
+		{"unterminated", "${ID", "${ID"},
+		{"invalid", "${1abc}", "${1abc}"},
+		{"lone dollar", "cost: $", "cost: $"},
+		{"int", "${int}", "42"},
+		{"int8", "${int8}", "-8"},
+		{"uint64", "${uint64}", "18446744073709551615"},
+		{"float", "${float}", "3.25"},
+		{"float32", "${float32}", "0.5"},
+		{"bool", "${bool}", "true"},
+		{"bytes", "${bytes}", "raw"},
+		{"list", "${list}", `["a","b","c"]`},
+		{"index", "${list[1]}", "b"},
+		{"nested", "${pod.name}", "web-0"},
+		{"nested struct", "${pod.spec.containers[0].image}", "nginx:1.25"},
+		{"nested struct field name", "${pod.spec.containers[0].Name}", "nginx"},
+		{"dotted name", "${exec.background.server.pid}", "1234"},
+		{"nested default", "${pod.ip:-none}", "none"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := variable.Expand(tc.subject, variable.MapLookup(vars))
+			require.Nil(t, err)
+			assert.Equal(t, tc.exp, got)
+		})
+	}
+}
+
+func TestExpandFieldNotFound(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject string
+	}{
+		{"missing key", "x ${pod.ip} y"},
+		{"index out of range", "${list[3]}"},
+		{"index non-list", "${pod[0]}"},
+		{"field of scalar", "${ID.name}"},
+		{"missing struct field", "${pod.spec.containers[0].ports}"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := variable.Expand(tc.subject, variable.MapLookup(vars))
+			require.ErrorIs(t, err, variable.ErrFieldNotFound)
+			assert.Equal(t, tc.subject, got)
+		})
+	}
+}
+
+func TestValue(t *testing.T) {
+	assert := assert.New(t)
+	lookup := variable.MapLookup(vars)
+
+	got, err := variable.Value("${int}", lookup)
+	assert.Nil(err)
+	assert.Equal(42, got)
+
+	got, err = variable.Value("$bool", lookup)
+	assert.Nil(err)
+	assert.Equal(true, got)
+
+	got, err = variable.Value("${pod.spec.containers[0]}", lookup)
+	assert.Nil(err)
+	assert.Equal(container{Name: "nginx", Image: "nginx:1.25"}, got)
+
+	got, err = variable.Value("${int}s", lookup)
+	assert.Nil(err)
+	assert.Equal("42s", got)
+
+	got, err = variable.Value("${missing}", lookup)
+	assert.Nil(err)
+	assert.Equal("${missing}", got)
+}